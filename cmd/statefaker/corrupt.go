@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/brandonc/go-statefaker.git/pkg/statefaker"
+)
+
+// runCorrupt implements "statefaker corrupt", which writes an invalid state
+// produced by applying a named corruption to a valid one
+func runCorrupt(args []string) error {
+	flags := flag.NewFlagSet("corrupt", flag.ExitOnError)
+	// The generation flags configure the state generated when there is no -in
+	var generation generateFlags
+	generation.register(flags)
+	mutation := flags.String("mutation", "", "the name of the corruption to apply, see -list")
+	in := flags.String("in", "", "the state to corrupt, or - for stdin (default a freshly generated state, which some corruptions do not allow)")
+	list := flags.Bool("list", false, "list the available corruptions and how each is expected to fail")
+	var output outputOptions
+	flags.StringVar(&output.path, "o", "", "the file to write the corrupted state to (default stdout)")
+	flags.Parse(args)
+
+	if *list {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tDESCRIPTION\tEXPECTED FAILURE\tREQUIRES -in")
+		for _, c := range statefaker.Corruptions() {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%t\n", c.Name, c.Description, c.ExpectedFailure, c.RequiresState)
+		}
+		return w.Flush()
+	}
+
+	if *mutation == "" {
+		return fmt.Errorf("a -mutation is required, use -list to see them")
+	}
+
+	// A regression is only visible against the state it regressed from
+	for _, c := range statefaker.Corruptions() {
+		if c.Name == *mutation && c.RequiresState && *in == "" {
+			return fmt.Errorf("%s can only be seen against the state it corrupts, so it requires -in", c.Name)
+		}
+	}
+
+	var state *statefaker.StateV4
+	var err error
+	if *in != "" {
+		state, err = readState(*in)
+	} else {
+		var opts []statefaker.Option
+		if opts, err = generation.options(); err != nil {
+			return err
+		}
+		state, err = statefaker.NewFakeStateV4(opts...)
+	}
+	if err != nil {
+		return err
+	}
+
+	b, err := statefaker.Corrupt(state, *mutation)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(output, func(w io.Writer) error {
+		_, err := w.Write(b)
+		return err
+	})
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/brandonc/go-statefaker.git/pkg/statefaker"
+)
+
+// runDiff implements "statefaker diff", which reports the structural
+// differences between two states
+func runDiff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "write the differences as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: statefaker diff [-json] <a> <b>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return fmt.Errorf("expected two state files")
+	}
+
+	a, err := readState(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	b, err := readState(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	diff, err := statefaker.Diff(a, b)
+	if err != nil {
+		return err
+	}
+	return writeDiff(os.Stdout, diff, *asJSON)
+}
+
+// diffSymbols are the markers used for each kind of change in the human
+// readable diff, as in a terraform plan
+var diffSymbols = map[statefaker.ChangeAction]string{
+	statefaker.ChangeAdded:   "+",
+	statefaker.ChangeRemoved: "-",
+	statefaker.ChangeChanged: "~",
+}
+
+// writeFieldChanges writes changes to fields other than attributes, with
+// their names in parentheses to tell them apart from attribute paths
+func writeFieldChanges(w io.Writer, fields []statefaker.FieldChange) {
+	for _, field := range fields {
+		fmt.Fprintf(w, "    ~ (%s): %s -> %s\n", field.Field, field.Before, field.After)
+	}
+}
+
+// writeDiff writes the differences as JSON, or in a format resembling a
+// terraform plan
+func writeDiff(w io.Writer, diff *statefaker.StateDiff, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diff)
+	}
+
+	if diff.Empty() {
+		_, err := fmt.Fprintln(w, "No differences.")
+		return err
+	}
+
+	for _, resource := range diff.Resources {
+		if resource.Action != statefaker.ChangeChanged || len(resource.Fields) > 0 {
+			fmt.Fprintf(w, "%s %s\n", diffSymbols[resource.Action], resource.Address)
+			writeFieldChanges(w, resource.Fields)
+		}
+		for _, instance := range resource.Instances {
+			fmt.Fprintf(w, "%s %s\n", diffSymbols[instance.Action], instance.Address)
+			writeFieldChanges(w, instance.Fields)
+			for _, attribute := range instance.Attributes {
+				path := attribute.Path
+				if path == "" {
+					path = "(attributes)"
+				}
+				switch attribute.Action {
+				case statefaker.ChangeAdded:
+					fmt.Fprintf(w, "    + %s: %s\n", path, attribute.After)
+				case statefaker.ChangeRemoved:
+					fmt.Fprintf(w, "    - %s: %s\n", path, attribute.Before)
+				default:
+					fmt.Fprintf(w, "    ~ %s: %s -> %s\n", path, attribute.Before, attribute.After)
+				}
+			}
+		}
+	}
+
+	for _, output := range diff.Outputs {
+		switch output.Action {
+		case statefaker.ChangeAdded:
+			fmt.Fprintf(w, "+ output.%s: %s\n", output.Name, output.After)
+		case statefaker.ChangeRemoved:
+			fmt.Fprintf(w, "- output.%s: %s\n", output.Name, output.Before)
+		default:
+			fmt.Fprintf(w, "~ output.%s: %s -> %s\n", output.Name, output.Before, output.After)
+		}
+	}
+	return nil
+}
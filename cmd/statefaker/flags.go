@@ -0,0 +1,166 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/brandonc/go-statefaker.git/pkg/statefaker"
+)
+
+// generateFlags are the flags that configure how states are generated,
+// shared by every command that generates states
+type generateFlags struct {
+	numOutputs           int
+	numResources         int
+	percentMultiInstance int
+	multiMaxInstances    int
+	multiMinInstances    int
+	percentModule        int
+	percentPrivate       int
+	privateSize          string
+	nameStyle            string
+	percentAlias         int
+	namespaces           string
+	registries           string
+	accounts             int
+	regions              int
+	tagsMin              int
+	tagsMax              int
+	defaultTags          int
+	percentHighTags      int
+	attributeSize        string
+	attributeSizeMax     string
+	terraformVersion     string
+	chaos                string
+	percentChaos         int
+}
+
+func (f *generateFlags) register(flags *flag.FlagSet) {
+	defaults := statefaker.DefaultOptions()
+
+	flags.IntVar(&f.numOutputs, "outputs", defaults.NumOutputs, "the number of outputs to generate")
+	flags.IntVar(&f.numResources, "resources", defaults.NumResources, "the number of resources to generate")
+	flags.IntVar(&f.percentMultiInstance, "pctmulti", defaults.MultiInstanceChance, "the percentage chance a resource is multi-instance")
+	flags.IntVar(&f.multiMaxInstances, "multimax", defaults.MultiInstanceMax, "the maximum number of instances for multi-instance resources")
+	flags.IntVar(&f.multiMinInstances, "multimin", defaults.MultiInstanceMin, "the minimum number of instances for multi-instance resources")
+	flags.IntVar(&f.percentModule, "pctmodule", defaults.ModuleChance, "the percentage chance a resource appears within a module")
+	flags.IntVar(&f.percentPrivate, "pctprivate", defaults.PrivateChance, "the percentage chance a managed resource instance has provider private data")
+	flags.StringVar(&f.privateSize, "privatesize", "typical", "the size distribution of provider private data: typical, large or pathological (format version 4 only, as format version 3 only stores timeouts)")
+	flags.StringVar(&f.nameStyle, "names", "snake", "the naming convention of resources, modules and outputs: snake, kebab, legacy or random")
+	flags.IntVar(&f.percentAlias, "pctalias", defaults.ProviderAliasChance, "the percentage chance a resource uses an aliased provider configuration")
+	flags.StringVar(&f.namespaces, "namespaces", strings.Join(defaults.ProviderNamespaces, ","), "comma separated registry namespaces providers are installed from")
+	flags.StringVar(&f.registries, "registries", strings.Join(defaults.RegistryHosts, ","), "comma separated registry hostnames providers are installed from")
+	flags.IntVar(&f.accounts, "accounts", defaults.NumAccounts, "the number of AWS accounts that aliased provider configurations spread resources across")
+	flags.IntVar(&f.regions, "regions", defaults.NumRegions, "the number of AWS regions that aliased provider configurations spread resources across")
+	flags.IntVar(&f.tagsMin, "tagsmin", defaults.TagCountMin, "the minimum number of tags on a taggable resource, besides provider default tags")
+	flags.IntVar(&f.tagsMax, "tagsmax", defaults.TagCountMax, "the maximum number of tags on a taggable resource, besides provider default tags")
+	flags.IntVar(&f.defaultTags, "defaulttags", defaults.DefaultTagCount, "the number of tags the provider's default_tags applies to every taggable resource")
+	flags.IntVar(&f.percentHighTags, "pcthightags", defaults.HighCardinalityTagChance, "the percentage chance a resource has tags whose values are unique to it, such as commit hashes")
+	flags.StringVar(&f.attributeSize, "attrsize", "0", "the average size of each resource instance's attributes, such as 500KB, or 0 to leave them at their natural size")
+	flags.StringVar(&f.attributeSizeMax, "attrsizemax", "0", "the largest size resource instance attributes are padded to, such as 2MB, or 0 for no limit")
+	flags.StringVar(&f.terraformVersion, "terraform-version", "", "the terraform version the state appears to be written by (default "+statefaker.DefaultTerraformVersionV4+", or "+statefaker.DefaultTerraformVersionV3+" for format version 3)")
+	flags.StringVar(&f.chaos, "chaos", "", "comma separated anomalies to include, or all (format version 4 only): "+anomalyNames())
+	flags.IntVar(&f.percentChaos, "pctchaos", defaults.AnomalyChance, "the percentage chance each anomaly affects an eligible resource or output")
+}
+
+// options returns the statefaker options described by the flags
+func (f *generateFlags) options() ([]statefaker.Option, error) {
+	size, err := statefaker.ParsePrivateSize(f.privateSize)
+	if err != nil {
+		return nil, err
+	}
+
+	nameStyle, err := statefaker.ParseNameStyle(f.nameStyle)
+	if err != nil {
+		return nil, err
+	}
+
+	anomalies, err := statefaker.ParseAnomalies(f.chaos)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces, err := statefaker.ParseProviderNamespaces(f.namespaces)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -namespaces: %w", err)
+	}
+	registries, err := statefaker.ParseRegistryHosts(f.registries)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -registries: %w", err)
+	}
+
+	attributeSize, err := parseByteSize(f.attributeSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -attrsize: %w", err)
+	}
+	attributeSizeMax, err := parseByteSize(f.attributeSizeMax)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -attrsizemax: %w", err)
+	}
+
+	return []statefaker.Option{
+		statefaker.WithOutputs(f.numOutputs),
+		statefaker.WithResources(f.numResources),
+		statefaker.WithMultiInstanceChance(f.percentMultiInstance),
+		statefaker.WithMultiInstanceMax(f.multiMaxInstances),
+		statefaker.WithMultiInstanceMin(f.multiMinInstances),
+		statefaker.WithModuleChance(f.percentModule),
+		statefaker.WithPrivateChance(f.percentPrivate),
+		statefaker.WithPrivateSize(size),
+		statefaker.WithNameStyle(nameStyle),
+		statefaker.WithProviderAliasChance(f.percentAlias),
+		statefaker.WithProviderNamespaces(namespaces...),
+		statefaker.WithRegistryHosts(registries...),
+		statefaker.WithAccounts(f.accounts),
+		statefaker.WithRegions(f.regions),
+		statefaker.WithTagCountMin(f.tagsMin),
+		statefaker.WithTagCountMax(f.tagsMax),
+		statefaker.WithDefaultTagCount(f.defaultTags),
+		statefaker.WithHighCardinalityTagChance(f.percentHighTags),
+		statefaker.WithAttributeSize(attributeSize),
+		statefaker.WithAttributeSizeMax(attributeSizeMax),
+		statefaker.WithTerraformVersion(f.terraformVersion),
+		statefaker.WithAnomalies(anomalies...),
+		statefaker.WithAnomalyChance(f.percentChaos),
+	}, nil
+}
+
+// byteSizeUnits are the suffixes parseByteSize accepts, largest first
+var byteSizeUnits = []struct {
+	suffix string
+	bytes  int
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// parseByteSize parses a size such as 500KB or 2MB into bytes. Sizes without
+// a unit are in bytes.
+func parseByteSize(size string) (int, error) {
+	size = strings.ToUpper(strings.TrimSpace(size))
+	multiplier := 1
+	for _, unit := range byteSizeUnits {
+		if strings.HasSuffix(size, unit.suffix) {
+			size = strings.TrimSpace(strings.TrimSuffix(size, unit.suffix))
+			multiplier = unit.bytes
+			break
+		}
+	}
+	n, err := strconv.Atoi(size)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("expected a size such as 500KB or 2MB, got %q", size)
+	}
+	return n * multiplier, nil
+}
+
+func anomalyNames() string {
+	var names []string
+	for _, anomaly := range statefaker.Anomalies() {
+		names = append(names, string(anomaly))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/brandonc/go-statefaker.git/pkg/statefaker"
+	"github.com/klauspost/compress/zstd"
+)
+
+// readState reads a format version 4 state from a file, or from stdin when
+// path is "-". States compressed with gzip or zstd are decompressed.
+func readState(path string) (*statefaker.StateV4, error) {
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open state: %w", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	r := bufio.NewReader(in)
+	magic, _ := r.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzip state: %w", err)
+		}
+		defer gz.Close()
+		in = gz
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read zstd state: %w", err)
+		}
+		defer zr.Close()
+		in = zr
+	default:
+		in = r
+	}
+
+	var state statefaker.StateV4
+	if err := json.NewDecoder(in).Decode(&state); err != nil {
+		return nil, fmt.Errorf("failed to decode state %s: %w", path, err)
+	}
+	if state.Version != 4 {
+		return nil, fmt.Errorf("state %s has format version %d, only version 4 is supported", path, state.Version)
+	}
+	return &state, nil
+}
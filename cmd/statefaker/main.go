@@ -1,51 +1,113 @@
 package main
 
 import (
-	"encoding/json"
+	"bytes"
 	"flag"
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/brandonc/go-statefaker.git/pkg/statefaker"
 )
 
-var numOutputs int
-var numResources int
-var percentMultiInstance int
-var multiMaxInstances int
-var multiMinInstances int
-var percentModule int
+var generation generateFlags
+var formatVersion int
+var output outputOptions
+var format string
+var passphrase string
+var keyProvider string
+var showStats bool
 
 func init() {
-	defaults := statefaker.DefaultOptions()
-
-	flag.IntVar(&numOutputs, "outputs", defaults.NumOutputs, "the number of outputs to generate")
-	flag.IntVar(&numResources, "resources", defaults.NumResources, "the number of resources to generate")
-	flag.IntVar(&percentMultiInstance, "pctmulti", defaults.MultiInstanceChance, "the percentage chance a resource is multi-instance")
-	flag.IntVar(&multiMaxInstances, "multimax", defaults.MultiInstanceMax, "the maximum number of instances for multi-instance resources")
-	flag.IntVar(&multiMinInstances, "multimin", defaults.MultiInstanceMin, "the minimum number of instances for multi-instance resources")
-	flag.IntVar(&percentModule, "pctmodule", defaults.ModuleChance, "the percentage chance a resource appears within a module")
+	generation.register(flag.CommandLine)
+
+	flag.IntVar(&formatVersion, "format-version", 4, "the state format version to generate: 3 or 4")
+	flag.StringVar(&format, "format", "compact", "the JSON formatting of the state: compact, or terraform to match terraform's on-disk formatting")
+	flag.StringVar(&passphrase, "encrypt-passphrase", os.Getenv("STATEFAKER_ENCRYPTION_PASSPHRASE"), "encrypt the state like OpenTofu's pbkdf2 key provider and aes_gcm method using this passphrase (default $STATEFAKER_ENCRYPTION_PASSPHRASE)")
+	flag.StringVar(&keyProvider, "encrypt-key-provider", "statefaker", "the name of the OpenTofu pbkdf2 key provider the state is encrypted with")
+	flag.StringVar(&output.path, "o", "", "the file to write the state to (default stdout)")
+	flag.StringVar(&output.compress, "compress", "", "compress the state with gzip or zstd")
+	flag.BoolVar(&output.base64, "base64", false, "base64 encode the state, after any compression")
+	flag.BoolVar(&output.checksums, "checksums", false, "write the size, MD5 and SHA256 of the state and of the output file to <file>.sums")
+	flag.BoolVar(&showStats, "stats", false, "write statistics about the generated state to stderr")
+}
+
+// commands are the subcommands of statefaker. Without a subcommand,
+// statefaker generates a state configured by the top level flags.
+var commands = map[string]func(args []string) error{
+	"corrupt": runCorrupt,
+	"diff":    runDiff,
+	"mutate":  runMutate,
+	"org":     runOrg,
+	"stats":   runStats,
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				panic(err)
+			}
+			return
+		}
+	}
+
 	flag.Parse()
 
-	sf, err := statefaker.NewFakeStateV4(
-		statefaker.WithOutputs(numOutputs),
-		statefaker.WithResources(numResources),
-		statefaker.WithMultiInstanceChance(percentMultiInstance),
-		statefaker.WithMultiInstanceMax(multiMaxInstances),
-		statefaker.WithMultiInstanceMin(multiMinInstances),
-		statefaker.WithModuleChance(percentModule),
-	)
+	if format != "compact" && format != "terraform" {
+		panic(fmt.Sprintf("unknown format %q, expected compact or terraform", format))
+	}
+	if showStats && formatVersion != 4 {
+		panic("statistics are only supported for format version 4")
+	}
+
+	opts, err := generation.options()
 	if err != nil {
 		panic(err)
 	}
 
-	// Marshal as json and print to stdout
-	b, err := json.Marshal(sf)
+	var sf any
+	switch formatVersion {
+	case 3:
+		sf, err = statefaker.NewFakeStateV3(opts...)
+	case 4:
+		sf, err = statefaker.NewFakeStateV4(opts...)
+	default:
+		err = fmt.Errorf("unsupported state format version %d", formatVersion)
+	}
 	if err != nil {
 		panic(err)
 	}
 
-	fmt.Println(string(b))
+	// Marshal as json and write to the configured output
+	err = writeOutput(output, func(w io.Writer) error {
+		if passphrase == "" {
+			return encodeState(w, sf, format)
+		}
+
+		// Encryption needs the whole state up front
+		var buf bytes.Buffer
+		if err := encodeState(&buf, sf, format); err != nil {
+			return err
+		}
+		b, err := statefaker.EncryptOpenTofu(buf.Bytes(), passphrase, keyProvider)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	if showStats {
+		stats, err := statefaker.NewStats(sf.(*statefaker.StateV4))
+		if err != nil {
+			panic(err)
+		}
+		if err := writeStats(os.Stderr, stats, false); err != nil {
+			panic(err)
+		}
+	}
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/brandonc/go-statefaker.git/pkg/statefaker"
+)
+
+// runMutate implements "statefaker mutate", which writes the state as it
+// might look after the next apply
+func runMutate(args []string) error {
+	flags := flag.NewFlagSet("mutate", flag.ExitOnError)
+	defaults := statefaker.DefaultMutationOptions()
+	in := flags.String("in", "", "the state to mutate, or - for stdin")
+	format := flags.String("format", "compact", "the JSON formatting of the state: compact, or terraform to match terraform's on-disk formatting")
+	percentDrift := flags.Int("pctdrift", defaults.DriftChance, "the percentage chance an instance's tags or size drift")
+	percentScale := flags.Int("pctscale", defaults.ScaleChance, "the percentage chance a count or for_each resource gains or loses instances")
+	percentMove := flags.Int("pctmove", defaults.MoveChance, "the percentage chance a resource moves to another module")
+	percentOutput := flags.Int("pctoutput", defaults.OutputChance, "the percentage chance an output's value changes")
+	percentDelete := flags.Int("pctdelete", defaults.DeleteChance, "the percentage chance a resource is deleted")
+	var output outputOptions
+	flags.StringVar(&output.path, "o", "", "the file to write the mutated state to (default stdout)")
+	flags.Parse(args)
+
+	if *in == "" {
+		return fmt.Errorf("an -in state is required")
+	}
+	if *format != "compact" && *format != "terraform" {
+		return fmt.Errorf("unknown format %q, expected compact or terraform", *format)
+	}
+
+	state, err := readState(*in)
+	if err != nil {
+		return err
+	}
+
+	next := statefaker.Mutate(state,
+		statefaker.WithDriftChance(*percentDrift),
+		statefaker.WithScaleChance(*percentScale),
+		statefaker.WithMoveChance(*percentMove),
+		statefaker.WithOutputChance(*percentOutput),
+		statefaker.WithDeleteChance(*percentDelete),
+	)
+
+	return writeOutput(output, func(w io.Writer) error {
+		return encodeState(w, next, *format)
+	})
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/brandonc/go-statefaker.git/pkg/statefaker"
+)
+
+// manifestEntry describes one workspace written by runOrg
+type manifestEntry struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Lineage   string `json:"lineage"`
+	Resources int    `json:"resources"`
+	Bytes     int64  `json:"bytes"`
+}
+
+// runOrg implements "statefaker org", which writes the states of a set of
+// workspaces in one organization to a directory, along with a manifest.json
+// describing each of them
+func runOrg(args []string) error {
+	flags := flag.NewFlagSet("org", flag.ExitOnError)
+	var generation generateFlags
+	generation.register(flags)
+	defaults := statefaker.DefaultOptions()
+	numWorkspaces := flags.Int("workspaces", 10, "the number of workspaces to generate")
+	out := flags.String("out", ".", "the directory to write workspace states to")
+	organization := flags.String("organization", "", "the name of the organization (default a generated name)")
+	size := flags.String("size", "lognormal", "the distribution of the number of resources in each workspace, with -resources as the median: fixed, lognormal or pareto")
+	percentRemote := flags.Int("pctremote", defaults.RemoteStateChance, "the percentage chance a workspace reads the outputs of other workspaces with terraform_remote_state")
+	flags.Parse(args)
+
+	workspaceSize, err := statefaker.ParseWorkspaceSize(*size)
+	if err != nil {
+		return err
+	}
+
+	opts, err := generation.options()
+	if err != nil {
+		return err
+	}
+	opts = append(opts,
+		statefaker.WithOrganization(*organization),
+		statefaker.WithRemoteStateChance(*percentRemote),
+		statefaker.WithWorkspaceSize(workspaceSize),
+	)
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	var manifest []manifestEntry
+	err = statefaker.GenerateWorkspaces(*numWorkspaces, func(workspace *statefaker.Workspace) error {
+		path := workspace.Name + ".tfstate"
+		output := outputOptions{path: filepath.Join(*out, path)}
+		err := writeOutput(output, func(w io.Writer) error {
+			return encodeState(w, workspace.State, "compact")
+		})
+		if err != nil {
+			return err
+		}
+
+		info, err := os.Stat(output.path)
+		if err != nil {
+			return err
+		}
+		manifest = append(manifest, manifestEntry{
+			Name:      workspace.Name,
+			Path:      path,
+			Lineage:   workspace.State.Lineage,
+			Resources: len(workspace.State.Resources),
+			Bytes:     info.Size(),
+		})
+		return nil
+	}, opts...)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(map[string]any{"workspaces": manifest}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(*out, "manifest.json"), append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/brandonc/go-statefaker.git/pkg/statefaker"
+	"github.com/klauspost/compress/zstd"
+)
+
+// outputOptions controls how a generated state is written
+type outputOptions struct {
+	path      string // the file to write, or empty for stdout
+	compress  string // gzip, zstd or empty for no compression
+	base64    bool   // wrap the (possibly compressed) output in base64
+	checksums bool   // write checksums to a sidecar file next to path
+}
+
+// encodeState writes a state as JSON in the given format. The compact format
+// is a single line, while the terraform format reproduces terraform's own
+// on-disk formatting so that states can be compared byte for byte.
+func encodeState(w io.Writer, state any, format string) error {
+	var b []byte
+	var err error
+
+	switch format {
+	case "compact":
+		return json.NewEncoder(w).Encode(state)
+	case "terraform":
+		switch s := state.(type) {
+		case *statefaker.StateV4:
+			b, err = statefaker.MarshalTerraform(s)
+		case *statefaker.StateV3:
+			b, err = statefaker.MarshalTerraformV3(s)
+		default:
+			b, err = json.MarshalIndent(s, "", "  ")
+			b = append(b, '\n')
+		}
+	default:
+		return fmt.Errorf("unknown format %q, expected compact or terraform", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// checksums describes the bytes that passed through a checksumWriter
+type checksums struct {
+	Size   int64  `json:"size"`
+	MD5    string `json:"md5"`
+	SHA256 string `json:"sha256"`
+}
+
+// checksumWriter hashes and counts everything written through it
+type checksumWriter struct {
+	w      io.Writer
+	size   int64
+	md5    hash.Hash
+	sha256 hash.Hash
+}
+
+func newChecksumWriter(w io.Writer) *checksumWriter {
+	return &checksumWriter{
+		w:      w,
+		md5:    md5.New(),
+		sha256: sha256.New(),
+	}
+}
+
+func (c *checksumWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.size += int64(n)
+	c.md5.Write(p[:n])
+	c.sha256.Write(p[:n])
+	return n, err
+}
+
+func (c *checksumWriter) sums() checksums {
+	return checksums{
+		Size:   c.size,
+		MD5:    hex.EncodeToString(c.md5.Sum(nil)),
+		SHA256: hex.EncodeToString(c.sha256.Sum(nil)),
+	}
+}
+
+// writeOutput writes the bytes produced by write to the configured
+// destination, compressing and encoding them on the way. The state is
+// streamed through each stage rather than buffered, so very large states
+// never need to be held in memory more than once.
+func writeOutput(opts outputOptions, write func(io.Writer) error) error {
+	if opts.checksums && opts.path == "" {
+		return fmt.Errorf("checksums require an output file")
+	}
+	if opts.compress != "" && opts.compress != "gzip" && opts.compress != "zstd" {
+		return fmt.Errorf("unknown compression %q, expected gzip or zstd", opts.compress)
+	}
+
+	var out io.WriteCloser = os.Stdout
+	if opts.path != "" {
+		f, err := os.Create(opts.path)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		out = f
+	}
+
+	// Stages are closed in reverse order so each flushes into the next
+	closers := []io.Closer{out}
+	fileSums := newChecksumWriter(out)
+	var w io.Writer = fileSums
+
+	if opts.base64 {
+		encoder := base64.NewEncoder(base64.StdEncoding, w)
+		closers = append(closers, encoder)
+		w = encoder
+	}
+
+	switch opts.compress {
+	case "gzip":
+		gz := gzip.NewWriter(w)
+		closers = append(closers, gz)
+		w = gz
+	case "zstd":
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			closeStages(closers)
+			return fmt.Errorf("failed to create zstd writer: %w", err)
+		}
+		closers = append(closers, zw)
+		w = zw
+	}
+
+	stateSums := newChecksumWriter(w)
+	err := write(stateSums)
+	if closeErr := closeStages(closers); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+
+	if opts.checksums {
+		return writeChecksums(opts.path+".sums", stateSums.sums(), fileSums.sums())
+	}
+	return nil
+}
+
+// closeStages closes output stages in reverse order, so each flushes into
+// the next, returning the first error. Stdout is left open.
+func closeStages(closers []io.Closer) error {
+	var err error
+	for i := len(closers) - 1; i >= 0; i-- {
+		if closers[i] == os.Stdout {
+			continue
+		}
+		if closeErr := closers[i].Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// writeChecksums writes the sidecar file describing both the raw state, as
+// needed by state upload APIs, and the file as written to disk
+func writeChecksums(path string, state, file checksums) error {
+	b, err := json.MarshalIndent(map[string]checksums{
+		"state": state,
+		"file":  file,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write checksums: %w", err)
+	}
+	return nil
+}
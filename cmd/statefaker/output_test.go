@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestWriteOutput(t *testing.T) {
+	state := []byte(strings.Repeat(`{"version":4,"resources":[]}`, 1000) + "\n")
+
+	for _, compress := range []string{"", "gzip", "zstd"} {
+		for _, encode := range []bool{false, true} {
+			name := compress + "-plain"
+			if encode {
+				name = compress + "-base64"
+			}
+			t.Run(name, func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "state.tfstate")
+				opts := outputOptions{path: path, compress: compress, base64: encode, checksums: true}
+				err := writeOutput(opts, func(w io.Writer) error {
+					_, err := w.Write(state)
+					return err
+				})
+				if err != nil {
+					t.Fatalf("failed to write output: %v", err)
+				}
+
+				file, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("failed to read output: %v", err)
+				}
+				decoded := decodeOutput(t, file, compress, encode)
+				if !bytes.Equal(decoded, state) {
+					t.Errorf("expected the output to decode to the state, got %d bytes", len(decoded))
+				}
+
+				b, err := os.ReadFile(path + ".sums")
+				if err != nil {
+					t.Fatalf("failed to read checksums: %v", err)
+				}
+				var sums map[string]checksums
+				if err := json.Unmarshal(b, &sums); err != nil {
+					t.Fatalf("failed to decode checksums: %v", err)
+				}
+				if expected := checksumsOf(state); sums["state"] != expected {
+					t.Errorf("expected state checksums %+v, got %+v", expected, sums["state"])
+				}
+				if expected := checksumsOf(file); sums["file"] != expected {
+					t.Errorf("expected file checksums %+v, got %+v", expected, sums["file"])
+				}
+			})
+		}
+	}
+}
+
+func TestWriteOutputErrors(t *testing.T) {
+	write := func(w io.Writer) error { return nil }
+	if err := writeOutput(outputOptions{checksums: true}, write); err == nil {
+		t.Error("expected an error writing checksums without an output file")
+	}
+	if err := writeOutput(outputOptions{path: filepath.Join(t.TempDir(), "state"), compress: "bzip2"}, write); err == nil {
+		t.Error("expected an error for an unknown compression")
+	}
+}
+
+// decodeOutput reverses the encoding and compression applied by writeOutput
+func decodeOutput(t *testing.T, file []byte, compress string, encoded bool) []byte {
+	t.Helper()
+	var r io.Reader = bytes.NewReader(file)
+	if encoded {
+		r = base64.NewDecoder(base64.StdEncoding, r)
+	}
+	switch compress {
+	case "gzip":
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			t.Fatalf("failed to read gzip output: %v", err)
+		}
+		defer gz.Close()
+		r = gz
+	case "zstd":
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			t.Fatalf("failed to read zstd output: %v", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to decode output: %v", err)
+	}
+	return b
+}
+
+func checksumsOf(b []byte) checksums {
+	md5Sum := md5.Sum(b)
+	sha256Sum := sha256.Sum256(b)
+	return checksums{
+		Size:   int64(len(b)),
+		MD5:    hex.EncodeToString(md5Sum[:]),
+		SHA256: hex.EncodeToString(sha256Sum[:]),
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/brandonc/go-statefaker.git/pkg/statefaker"
+)
+
+// runStats implements "statefaker stats", which reports what a state
+// contains
+func runStats(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "write the statistics as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: statefaker stats [-json] <file|->")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected a single state file")
+	}
+
+	state, err := readState(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	stats, err := statefaker.NewStats(state)
+	if err != nil {
+		return err
+	}
+	return writeStats(os.Stdout, stats, *asJSON)
+}
+
+// writeStats writes statistics as JSON, or as a human readable report
+func writeStats(out io.Writer, stats *statefaker.Stats, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Resources\t%d\n", stats.Resources)
+	fmt.Fprintf(w, "Instances\t%d (%d tainted, %d deposed objects)\n", stats.Instances, stats.TaintedInstances, stats.DeposedObjects)
+	fmt.Fprintf(w, "Outputs\t%d\n", stats.Outputs)
+	fmt.Fprintf(w, "Sensitive outputs\t%d\n", stats.SensitiveOutputs)
+	fmt.Fprintf(w, "Sensitive instances\t%d (%d paths)\n", stats.SensitiveInstances, stats.SensitivePaths)
+	fmt.Fprintf(w, "Attribute bytes\tp50 %d, p90 %d, p99 %d, max %d\n",
+		stats.AttributeSizes.P50, stats.AttributeSizes.P90, stats.AttributeSizes.P99, stats.AttributeSizes.Max)
+
+	writeCounts(w, "Resources by type", stats.ResourcesByType)
+	writeCounts(w, "Resources by mode", stats.ResourcesByMode)
+	writeCounts(w, "Resources by provider", stats.ResourcesByProvider)
+	writeCounts(w, "Resources by module", stats.ResourcesByModule)
+	writeCounts(w, "Outputs by type", stats.OutputsByType)
+
+	fmt.Fprintln(w, "\nInstances per resource")
+	for _, n := range slices.Sorted(maps.Keys(stats.InstancesPerResource)) {
+		fmt.Fprintf(w, "  %d\t%d\n", n, stats.InstancesPerResource[n])
+	}
+
+	fmt.Fprintln(w, "\nLargest resources")
+	for _, r := range stats.LargestResources {
+		fmt.Fprintf(w, "  %s\t%d bytes\n", r.Address, r.Bytes)
+	}
+
+	return w.Flush()
+}
+
+// writeCounts writes a titled table of counts, largest first
+func writeCounts(w io.Writer, title string, counts map[string]int) {
+	fmt.Fprintf(w, "\n%s\n", title)
+	keys := slices.Sorted(maps.Keys(counts))
+	slices.SortStableFunc(keys, func(a, b string) int {
+		return counts[b] - counts[a]
+	})
+	for _, key := range keys {
+		fmt.Fprintf(w, "  %s\t%d\n", key, counts[key])
+	}
+}
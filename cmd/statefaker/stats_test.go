@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brandonc/go-statefaker.git/pkg/statefaker"
+	"github.com/klauspost/compress/zstd"
+)
+
+// countFixture is a state written the way terraform writes it, with a
+// resource using count, a tainted instance and a deposed object
+const countFixture = "../../pkg/statefaker/testdata/count.tfstate"
+
+func TestStatsOfTerraformState(t *testing.T) {
+	fixture, err := os.ReadFile(countFixture)
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	dir := t.TempDir()
+	var gz, zst bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write(fixture)
+	gw.Close()
+	zw, _ := zstd.NewWriter(&zst)
+	zw.Write(fixture)
+	zw.Close()
+	for name, contents := range map[string][]byte{"gzip": gz.Bytes(), "zstd": zst.Bytes()} {
+		if err := os.WriteFile(filepath.Join(dir, name), contents, 0o644); err != nil {
+			t.Fatalf("failed to write %s state: %v", name, err)
+		}
+	}
+
+	for _, path := range []string{countFixture, filepath.Join(dir, "gzip"), filepath.Join(dir, "zstd")} {
+		state, err := readState(path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		stats, err := statefaker.NewStats(state)
+		if err != nil {
+			t.Fatalf("failed to compute stats of %s: %v", path, err)
+		}
+
+		if stats.Resources != 3 || stats.Instances != 5 || stats.TaintedInstances != 1 || stats.DeposedObjects != 1 {
+			t.Errorf("expected 3 resources and 5 instances, 1 tainted, with 1 deposed object, got %d and %d, %d tainted, with %d deposed objects",
+				stats.Resources, stats.Instances, stats.TaintedInstances, stats.DeposedObjects)
+		}
+		if stats.InstancesPerResource[3] != 1 || stats.InstancesPerResource[1] != 2 {
+			t.Errorf("unexpected instances per resource %v", stats.InstancesPerResource)
+		}
+		if stats.AttributeSizes.P50 == 0 {
+			t.Errorf("expected attribute sizes, got %+v", stats.AttributeSizes)
+		}
+
+		var report bytes.Buffer
+		if err := writeStats(&report, stats, false); err != nil {
+			t.Fatalf("failed to write stats: %v", err)
+		}
+		if !strings.Contains(report.String(), "aws_instance.web") {
+			t.Errorf("expected aws_instance.web among the largest resources, got:\n%s", report.String())
+		}
+	}
+}
@@ -5,6 +5,8 @@ go 1.24.0
 toolchain go1.24.7
 
 require (
-	github.com/go-faker/faker/v4 v4.7.0 // indirect
-	golang.org/x/text v0.29.0 // indirect
+	github.com/go-faker/faker/v4 v4.7.0
+	github.com/klauspost/compress v1.18.0
 )
+
+require golang.org/x/text v0.29.0 // indirect
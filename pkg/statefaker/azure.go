@@ -0,0 +1,263 @@
+package statefaker
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/go-faker/faker/v4"
+)
+
+// azureRegions are the Azure locations that generated azurerm resources live in
+var azureRegions = []string{
+	"eastus", "eastus2", "westus2", "centralus", "northeurope", "westeurope", "uksouth", "australiaeast", "southeastasia",
+}
+
+// azureLocation is the tenant, subscription and default resource group that
+// every azurerm resource in a state belongs to
+type azureLocation struct {
+	TenantID       string
+	SubscriptionID string
+	ResourceGroup  string
+	Location       string
+}
+
+func generateAzureLocation() azureLocation {
+	return azureLocation{
+		TenantID:       faker.UUIDHyphenated(),
+		SubscriptionID: faker.UUIDHyphenated(),
+		ResourceGroup:  fmt.Sprintf("rg-%s-%s", faker.Word(), workspaceEnvs[rand.IntN(len(workspaceEnvs))]),
+		Location:       azureRegions[rand.IntN(len(azureRegions))],
+	}
+}
+
+// resourceGroupID returns the ID of a resource group in the subscription
+func (l azureLocation) resourceGroupID(resourceGroup string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", l.SubscriptionID, resourceGroup)
+}
+
+// resourceID returns the ID of a resource in a resource group, such as
+// /subscriptions/<id>/resourceGroups/<group>/providers/Microsoft.Network/virtualNetworks/<name>
+func (l azureLocation) resourceID(resourceGroup, provider, resource string) string {
+	return fmt.Sprintf("%s/providers/%s/%s", l.resourceGroupID(resourceGroup), provider, resource)
+}
+
+// azureResourceGroup returns the name and location of an existing resource
+// group for a new azurerm resource, or the state's default resource group
+// when there is none
+func (c *attributeContext) azureResourceGroup() (name, location string) {
+	if groups := c.pick("azurerm_resource_group", 1, 1); len(groups) > 0 {
+		return groups[0]["name"].(string), groups[0]["location"].(string)
+	}
+	return c.azure.ResourceGroup, c.azure.Location
+}
+
+// azureTags returns the tags of a new azurerm resource. The azurerm provider
+// has no default tags, so there is no tags_all.
+func (c *attributeContext) azureTags() map[string]string {
+	tags, _ := c.tags(nil)
+	return tags
+}
+
+func generateAzureResourceGroupAttributes(ctx *attributeContext) map[string]any {
+	name := fmt.Sprintf("rg-%s", strings.ReplaceAll(ctx.name(), "-", ""))
+	return map[string]any{
+		"id":         ctx.azure.resourceGroupID(name),
+		"name":       name,
+		"location":   ctx.azure.Location,
+		"managed_by": "",
+		"tags":       ctx.azureTags(),
+		"timeouts":   nil,
+	}
+}
+
+func generateAzureVirtualNetworkAttributes(ctx *attributeContext) map[string]any {
+	name := fmt.Sprintf("vnet-%s", ctx.name())
+	resourceGroup, location := ctx.azureResourceGroup()
+	return map[string]any{
+		"id":                      ctx.azure.resourceID(resourceGroup, "Microsoft.Network", "virtualNetworks/"+name),
+		"name":                    name,
+		"resource_group_name":     resourceGroup,
+		"location":                location,
+		"address_space":           []string{fmt.Sprintf("10.%d.0.0/16", rand.IntN(256))},
+		"dns_servers":             []string{},
+		"guid":                    faker.UUIDHyphenated(),
+		"subnet":                  []map[string]any{},
+		"bgp_community":           "",
+		"edge_zone":               "",
+		"flow_timeout_in_minutes": 0,
+		"ddos_protection_plan":    []map[string]any{},
+		"encryption":              []map[string]any{},
+		"tags":                    ctx.azureTags(),
+		"timeouts":                nil,
+	}
+}
+
+func generateAzureSubnetAttributes(ctx *attributeContext) map[string]any {
+	name := fmt.Sprintf("snet-%s", []string{"app", "data", "aks", "private-endpoints", "gateway"}[rand.IntN(5)])
+	resourceGroup, _ := ctx.azureResourceGroup()
+	virtualNetwork := fmt.Sprintf("vnet-%s", ctx.name())
+	addressPrefix := fmt.Sprintf("10.%d.%d.0/24", rand.IntN(256), rand.IntN(256))
+	// Subnets are carved out of an existing virtual network's address space,
+	// in its resource group
+	if networks := ctx.pick("azurerm_virtual_network", 1, 1); len(networks) > 0 {
+		resourceGroup = networks[0]["resource_group_name"].(string)
+		virtualNetwork = networks[0]["name"].(string)
+		addressPrefix = strings.Replace(networks[0]["address_space"].([]string)[0], ".0.0/16", fmt.Sprintf(".%d.0/24", rand.IntN(256)), 1)
+	}
+	return map[string]any{
+		"id":                                ctx.azure.resourceID(resourceGroup, "Microsoft.Network", fmt.Sprintf("virtualNetworks/%s/subnets/%s", virtualNetwork, name)),
+		"name":                              name,
+		"resource_group_name":               resourceGroup,
+		"virtual_network_name":              virtualNetwork,
+		"address_prefixes":                  []string{addressPrefix},
+		"service_endpoints":                 pickStrings([]string{"Microsoft.Storage", "Microsoft.KeyVault", "Microsoft.Sql"}, rand.IntN(3)),
+		"delegation":                        []map[string]any{},
+		"private_endpoint_network_policies": "Disabled",
+		"private_link_service_network_policies_enabled": true,
+		"default_outbound_access_enabled":               true,
+		"timeouts":                                      nil,
+	}
+}
+
+func generateAzureLinuxVirtualMachineAttributes(ctx *attributeContext) map[string]any {
+	name := fmt.Sprintf("vm-%s", ctx.name())
+	resourceGroup, location := ctx.azureResourceGroup()
+	// Virtual machines are attached to an existing subnet through a network
+	// interface in the subnet's resource group
+	privateIP := fmt.Sprintf("10.%d.%d.%d", rand.IntN(256), rand.IntN(256), rand.IntN(254)+1)
+	if subnets := ctx.pick("azurerm_subnet", 1, 1); len(subnets) > 0 {
+		resourceGroup = subnets[0]["resource_group_name"].(string)
+		prefix := subnets[0]["address_prefixes"].([]string)[0]
+		privateIP = strings.Replace(prefix, ".0/24", fmt.Sprintf(".%d", rand.IntN(250)+4), 1)
+	}
+	adminUsername := []string{"azureuser", "adminuser", "ubuntu"}[rand.IntN(3)]
+	return map[string]any{
+		"id":                              ctx.azure.resourceID(resourceGroup, "Microsoft.Compute", "virtualMachines/"+name),
+		"name":                            name,
+		"computer_name":                   name,
+		"resource_group_name":             resourceGroup,
+		"location":                        location,
+		"size":                            []string{"Standard_B2s", "Standard_D2s_v5", "Standard_D4s_v5", "Standard_E4s_v5"}[rand.IntN(4)],
+		"zone":                            []string{"1", "2", "3"}[rand.IntN(3)],
+		"admin_username":                  adminUsername,
+		"admin_password":                  nil,
+		"custom_data":                     nil,
+		"network_interface_ids":           []string{ctx.azure.resourceID(resourceGroup, "Microsoft.Network", fmt.Sprintf("networkInterfaces/nic-%s", name))},
+		"private_ip_address":              privateIP,
+		"private_ip_addresses":            []string{privateIP},
+		"public_ip_address":               "",
+		"public_ip_addresses":             []string{},
+		"virtual_machine_id":              faker.UUIDHyphenated(),
+		"disable_password_authentication": true,
+		"admin_ssh_key": []map[string]any{
+			{
+				"username":   adminUsername,
+				"public_key": fmt.Sprintf("ssh-rsa AAAAB3NzaC1yc2E%s %s@%s", faker.Password()+faker.Password()+faker.Password(), adminUsername, faker.DomainName()),
+			},
+		},
+		"os_disk": []map[string]any{
+			{
+				"name":                      fmt.Sprintf("%s_OsDisk_1_%s", name, faker.UUIDDigit()),
+				"caching":                   "ReadWrite",
+				"storage_account_type":      []string{"Standard_LRS", "Premium_LRS", "StandardSSD_LRS"}[rand.IntN(3)],
+				"disk_size_gb":              []int{30, 64, 128}[rand.IntN(3)],
+				"write_accelerator_enabled": false,
+			},
+		},
+		"source_image_reference": []map[string]any{
+			{
+				"publisher": "Canonical",
+				"offer":     "0001-com-ubuntu-server-jammy",
+				"sku":       "22_04-lts-gen2",
+				"version":   "latest",
+			},
+		},
+		"identity": []map[string]any{
+			{
+				"type":         "SystemAssigned",
+				"principal_id": faker.UUIDHyphenated(),
+				"tenant_id":    ctx.azure.TenantID,
+				"identity_ids": []string{},
+			},
+		},
+		"tags":     ctx.azureTags(),
+		"timeouts": nil,
+	}
+}
+
+func generateAzureStorageAccountAttributes(ctx *attributeContext) map[string]any {
+	// Storage account names are 3 to 24 lowercase letters and digits
+	name := strings.ToLower(faker.Word()) + faker.UUIDDigit()
+	name = name[:min(len(name), 24)]
+	resourceGroup, location := ctx.azureResourceGroup()
+	accessKey := faker.Password() + faker.Password() + "=="
+	endpoint := func(service string) string {
+		return fmt.Sprintf("https://%s.%s.core.windows.net/", name, service)
+	}
+	return map[string]any{
+		"id":                            ctx.azure.resourceID(resourceGroup, "Microsoft.Storage", "storageAccounts/"+name),
+		"name":                          name,
+		"resource_group_name":           resourceGroup,
+		"location":                      location,
+		"account_kind":                  "StorageV2",
+		"account_tier":                  []string{"Standard", "Premium"}[rand.IntN(2)],
+		"account_replication_type":      []string{"LRS", "GRS", "ZRS", "RAGRS"}[rand.IntN(4)],
+		"access_tier":                   "Hot",
+		"https_traffic_only_enabled":    true,
+		"min_tls_version":               "TLS1_2",
+		"public_network_access_enabled": rand.IntN(2) == 0,
+		"primary_location":              location,
+		"primary_blob_endpoint":         endpoint("blob"),
+		"primary_queue_endpoint":        endpoint("queue"),
+		"primary_table_endpoint":        endpoint("table"),
+		"primary_file_endpoint":         endpoint("file"),
+		"primary_access_key":            accessKey,
+		"primary_connection_string":     fmt.Sprintf("DefaultEndpointsProtocol=https;AccountName=%s;AccountKey=%s;EndpointSuffix=core.windows.net", name, accessKey),
+		"blob_properties":               []map[string]any{{"versioning_enabled": rand.IntN(2) == 0, "change_feed_enabled": false}},
+		"network_rules":                 []map[string]any{},
+		"tags":                          ctx.azureTags(),
+		"timeouts":                      nil,
+	}
+}
+
+func generateAzureKeyVaultAttributes(ctx *attributeContext) map[string]any {
+	// Key vault names are globally unique and at most 24 characters
+	name := fmt.Sprintf("kv-%s-%s", faker.Word(), faker.UUIDDigit()[:6])
+	name = name[:min(len(name), 24)]
+	resourceGroup, location := ctx.azureResourceGroup()
+
+	accessPolicies := make([]map[string]any, rand.IntN(3)+1)
+	for i := range accessPolicies {
+		accessPolicies[i] = map[string]any{
+			"tenant_id":               ctx.azure.TenantID,
+			"object_id":               faker.UUIDHyphenated(),
+			"application_id":          "",
+			"key_permissions":         pickStrings([]string{"Get", "List", "Create", "Delete", "Encrypt", "Decrypt", "WrapKey", "UnwrapKey"}, rand.IntN(8)+1),
+			"secret_permissions":      pickStrings([]string{"Get", "List", "Set", "Delete", "Recover", "Backup", "Restore"}, rand.IntN(7)+1),
+			"certificate_permissions": pickStrings([]string{"Get", "List", "Create", "Import", "Update"}, rand.IntN(5)),
+			"storage_permissions":     []string{},
+		}
+	}
+	return map[string]any{
+		"id":                              ctx.azure.resourceID(resourceGroup, "Microsoft.KeyVault", "vaults/"+name),
+		"name":                            name,
+		"resource_group_name":             resourceGroup,
+		"location":                        location,
+		"tenant_id":                       ctx.azure.TenantID,
+		"sku_name":                        []string{"standard", "premium"}[rand.IntN(2)],
+		"vault_uri":                       fmt.Sprintf("https://%s.vault.azure.net/", name),
+		"access_policy":                   accessPolicies,
+		"enable_rbac_authorization":       false,
+		"enabled_for_deployment":          false,
+		"enabled_for_disk_encryption":     rand.IntN(2) == 0,
+		"enabled_for_template_deployment": false,
+		"public_network_access_enabled":   true,
+		"purge_protection_enabled":        rand.IntN(2) == 0,
+		"soft_delete_retention_days":      90,
+		"network_acls":                    []map[string]any{{"bypass": "AzureServices", "default_action": "Allow", "ip_rules": []string{}, "virtual_network_subnet_ids": []string{}}},
+		"contact":                         []map[string]any{},
+		"tags":                            ctx.azureTags(),
+		"timeouts":                        nil,
+	}
+}
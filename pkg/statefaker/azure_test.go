@@ -0,0 +1,80 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// azureResourceID matches the subscription and resource group of an Azure resource ID
+var azureResourceID = regexp.MustCompile(`^/subscriptions/([0-9a-f-]{36})/resourceGroups/([^/]+)(/providers/Microsoft\.\w+/.+)?$`)
+
+func TestAzureResources(t *testing.T) {
+	state, err := NewFakeStateV4(WithResources(300))
+	if err != nil {
+		t.Fatalf("failed to generate fake state: %v", err)
+	}
+
+	subscriptions := make(map[string]bool)
+	found := 0
+	for _, resource := range state.Resources {
+		if !strings.HasPrefix(resource.Type, "azurerm_") {
+			continue
+		}
+		found++
+		if !strings.Contains(resource.Provider, `/azurerm"]`) {
+			t.Errorf("%s uses provider %s", resourceAddress(resource), resource.Provider)
+		}
+
+		for _, instance := range resource.Instances {
+			var attributes struct {
+				ID                string `json:"id"`
+				Name              string `json:"name"`
+				ResourceGroupName string `json:"resource_group_name"`
+			}
+			if err := json.Unmarshal(instance.Attributes, &attributes); err != nil {
+				t.Fatalf("failed to decode attributes: %v", err)
+			}
+			match := azureResourceID.FindStringSubmatch(attributes.ID)
+			if match == nil {
+				t.Errorf("%s has an unexpected ID %s", resourceAddress(resource), attributes.ID)
+				continue
+			}
+			subscriptions[match[1]] = true
+
+			resourceGroup := attributes.ResourceGroupName
+			if resource.Type == "azurerm_resource_group" {
+				resourceGroup = attributes.Name
+			}
+			if match[2] != resourceGroup {
+				t.Errorf("%s is in resource group %s, but its ID %s is not", resourceAddress(resource), resourceGroup, attributes.ID)
+			}
+			if !strings.HasSuffix(attributes.ID, "/"+attributes.Name) {
+				t.Errorf("%s ID %s does not end with its name %s", resourceAddress(resource), attributes.ID, attributes.Name)
+			}
+		}
+	}
+
+	if found == 0 {
+		t.Fatal("expected azurerm resources")
+	}
+	if len(subscriptions) != 1 {
+		t.Errorf("expected every azurerm resource to share one subscription, got %v", subscriptions)
+	}
+}
+
+func TestGetProviderFromResourceType(t *testing.T) {
+	for resourceType, expected := range map[string]string{
+		"aws_instance":            "aws",
+		"azurerm_resource_group":  "azurerm",
+		"google_compute_instance": "google",
+		"kubernetes_deployment":   "kubernetes",
+		"helm_release":            "helm",
+		"random_id":               "aws",
+	} {
+		if provider := getProviderFromResourceType(resourceType); provider != expected {
+			t.Errorf("expected %s to belong to the %s provider, got %s", resourceType, expected, provider)
+		}
+	}
+}
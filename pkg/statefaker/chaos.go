@@ -0,0 +1,242 @@
+package statefaker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+// Anomaly is a kind of pathological but valid data that real states have
+// been seen to contain, and that parsers tend to fall over on
+type Anomaly string
+
+const (
+	// AnomalyUnicodeNames gives resources and for_each keys non-ASCII names,
+	// including emoji
+	AnomalyUnicodeNames Anomaly = "unicode-names"
+	// AnomalyEscapedKeys gives for_each keys quotes and backslashes
+	AnomalyEscapedKeys Anomaly = "escaped-keys"
+	// AnomalyDeepNesting adds an attribute nested hundreds of levels deep
+	AnomalyDeepNesting Anomaly = "deep-nesting"
+	// AnomalyHugeStrings adds a single 10 MB attribute string to one
+	// instance in the state
+	AnomalyHugeStrings Anomaly = "huge-strings"
+	// AnomalyHugeNumbers adds numbers that cannot be represented as a float64
+	// without losing precision to attributes and outputs
+	AnomalyHugeNumbers Anomaly = "huge-numbers"
+	// AnomalyEmptyInstances leaves resources with an empty instances array
+	AnomalyEmptyInstances Anomaly = "empty-instances"
+	// AnomalyNullValues sets instance attributes and output values to null
+	AnomalyNullValues Anomaly = "null-values"
+	// AnomalyCaseOutputs adds outputs whose names differ from another output
+	// only by case
+	AnomalyCaseOutputs Anomaly = "case-outputs"
+)
+
+// Anomalies returns every known anomaly
+func Anomalies() []Anomaly {
+	return []Anomaly{
+		AnomalyUnicodeNames,
+		AnomalyEscapedKeys,
+		AnomalyDeepNesting,
+		AnomalyHugeStrings,
+		AnomalyHugeNumbers,
+		AnomalyEmptyInstances,
+		AnomalyNullValues,
+		AnomalyCaseOutputs,
+	}
+}
+
+// ParseAnomalies parses a comma separated list of anomalies, or "all"
+func ParseAnomalies(s string) ([]Anomaly, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if s == "all" {
+		return Anomalies(), nil
+	}
+
+	var anomalies []Anomaly
+	for _, name := range strings.Split(s, ",") {
+		anomaly := Anomaly(strings.TrimSpace(name))
+		known := false
+		for _, a := range Anomalies() {
+			known = known || a == anomaly
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown anomaly %q", name)
+		}
+		anomalies = append(anomalies, anomaly)
+	}
+	return anomalies, nil
+}
+
+// Names and keys that are valid but awkward for anything that assumes ASCII
+var (
+	unicodeNames = []string{
+		"données_clients", "日本語_リソース", "сервер_основной", "🚀_launch", "cafe\u0301_menu",
+		"العربية_قاعدة", "emoji_🔥_🐛", "ελληνικά", "zero\u200bwidth", "한국어_서버",
+	}
+	escapedKeys = []string{
+		`say "hello"`, `C:\Users\admin`, `\"already escaped\"`, `trailing\`, "tab\tseparated",
+		"new\nline", `{"json":"key"}`, `it's`, `back\\slashes\\\\`, `<script>&amp;`,
+	}
+)
+
+// applyAnomalies mutates a well-behaved state to contain the given
+// anomalies, each affecting roughly chance percent of the eligible resources
+// or outputs
+func applyAnomalies(state *StateV4, anomalies []Anomaly, chance int) error {
+	enabled := make(map[Anomaly]bool)
+	for _, anomaly := range anomalies {
+		enabled[anomaly] = true
+	}
+	hit := func() bool { return rand.IntN(100) < chance }
+
+	renamed := make(map[string]string)
+	for i := range state.Resources {
+		resource := &state.Resources[i]
+
+		if enabled[AnomalyUnicodeNames] && hit() {
+			address := resourceAddress(*resource)
+			// Keep the suffix so that the address stays unique
+			resource.Name = fmt.Sprintf("%s_%d", unicodeNames[rand.IntN(len(unicodeNames))], i)
+			if len(resource.Instances) > 1 {
+				for j := range resource.Instances {
+					resource.Instances[j].IndexKey = fmt.Sprintf("%s %d", unicodeNames[rand.IntN(len(unicodeNames))], j)
+				}
+			}
+			renamed[address] = resourceAddress(*resource)
+		}
+
+		if enabled[AnomalyEscapedKeys] && len(resource.Instances) > 1 && hit() {
+			for j := range resource.Instances {
+				resource.Instances[j].IndexKey = fmt.Sprintf("%s %d", escapedKeys[rand.IntN(len(escapedKeys))], j)
+			}
+		}
+
+		for j := range resource.Instances {
+			instance := &resource.Instances[j]
+
+			if enabled[AnomalyDeepNesting] && hit() {
+				if err := setAttribute(instance, "nested", deeplyNested(rand.IntN(400)+100)); err != nil {
+					return err
+				}
+			}
+			if enabled[AnomalyHugeNumbers] && hit() {
+				if err := setAttribute(instance, "huge_number", hugeNumber()); err != nil {
+					return err
+				}
+			}
+			if enabled[AnomalyNullValues] && hit() {
+				instance.Attributes = json.RawMessage("null")
+			}
+		}
+
+		if enabled[AnomalyEmptyInstances] && hit() {
+			resource.Instances = []InstanceV4{}
+		}
+	}
+
+	// Keep dependencies pointing at the resources they depended on
+	for i := range state.Resources {
+		for j := range state.Resources[i].Instances {
+			for k, dependency := range state.Resources[i].Instances[j].Dependencies {
+				if newAddress, ok := renamed[dependency]; ok {
+					state.Resources[i].Instances[j].Dependencies[k] = newAddress
+				}
+			}
+			slices.Sort(state.Resources[i].Instances[j].Dependencies)
+		}
+	}
+
+	if enabled[AnomalyHugeStrings] {
+		// A single 10 MB string is pathological enough for one state
+		for i := range state.Resources {
+			if len(state.Resources[i].Instances) == 0 || string(state.Resources[i].Instances[0].Attributes) == "null" {
+				continue
+			}
+			if err := setAttribute(&state.Resources[i].Instances[0], "user_data", strings.Repeat("#!/bin/bash\necho statefaker\n", 10*1024*1024/28)); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	var names []string
+	for name := range state.Outputs {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		if enabled[AnomalyHugeNumbers] && hit() {
+			state.Outputs[name] = json.RawMessage(fmt.Sprintf(`{"value":%s,"type":"number"}`, hugeNumber()))
+		}
+		if enabled[AnomalyNullValues] && hit() {
+			state.Outputs[name] = json.RawMessage(`{"value":null,"type":"string"}`)
+		}
+		if enabled[AnomalyCaseOutputs] && hit() {
+			duplicate := strings.ToUpper(name)
+			if duplicate == name {
+				duplicate = strings.ToLower(name)
+			}
+			state.Outputs[duplicate] = state.Outputs[name]
+		}
+	}
+
+	return nil
+}
+
+// setAttribute sets a top level attribute of an instance, preserving the
+// precision of any numbers already in the attributes
+func setAttribute(instance *InstanceV4, name string, value any) error {
+	decoder := json.NewDecoder(bytes.NewReader(instance.Attributes))
+	decoder.UseNumber()
+
+	var attributes map[string]any
+	if err := decoder.Decode(&attributes); err != nil {
+		return fmt.Errorf("failed to decode attributes: %w", err)
+	}
+	if attributes == nil {
+		attributes = make(map[string]any)
+	}
+	attributes[name] = value
+
+	b, err := json.Marshal(attributes)
+	if err != nil {
+		return fmt.Errorf("failed to encode attributes: %w", err)
+	}
+	instance.Attributes = b
+	return nil
+}
+
+// deeplyNested returns a value nested depth levels deep
+func deeplyNested(depth int) any {
+	var value any = "bottom"
+	for i := 0; i < depth; i++ {
+		if i%2 == 0 {
+			value = map[string]any{"child": value}
+		} else {
+			value = []any{value}
+		}
+	}
+	return value
+}
+
+// hugeNumber returns a number with more digits than a float64 can hold
+func hugeNumber() json.Number {
+	var digits strings.Builder
+	digits.WriteString(fmt.Sprint(rand.IntN(9) + 1))
+	for range rand.IntN(40) + 20 {
+		digits.WriteString(fmt.Sprint(rand.IntN(10)))
+	}
+	if rand.IntN(2) == 0 {
+		digits.WriteString(".")
+		for range rand.IntN(20) + 1 {
+			digits.WriteString(fmt.Sprint(rand.IntN(10)))
+		}
+	}
+	return json.Number(digits.String())
+}
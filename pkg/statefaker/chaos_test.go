@@ -0,0 +1,180 @@
+package statefaker
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestAnomalies(t *testing.T) {
+	state, err := NewFakeStateV4(
+		WithResources(100),
+		WithOutputs(20),
+		WithMultiInstanceChance(50),
+		WithMultiInstanceMax(3),
+		WithAnomalies(AnomalyHugeNumbers, AnomalyEmptyInstances, AnomalyCaseOutputs),
+		WithAnomalyChance(100),
+	)
+	if err != nil {
+		t.Fatalf("failed to generate fake state: %v", err)
+	}
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("failed to marshal state: %v", err)
+	}
+	if !json.Valid(b) {
+		t.Fatal("state is not valid JSON")
+	}
+
+	for _, resource := range state.Resources {
+		if len(resource.Instances) != 0 {
+			t.Errorf("expected %s.%s to have no instances", resource.Type, resource.Name)
+		}
+	}
+
+	lower := make(map[string]int)
+	for name, output := range state.Outputs {
+		lower[strings.ToLower(name)]++
+		// The huge number survives a round trip without losing precision
+		decoder := json.NewDecoder(bytes.NewReader(output))
+		decoder.UseNumber()
+		var value struct{ Value json.Number }
+		if err := decoder.Decode(&value); err != nil {
+			t.Fatalf("failed to decode output %s: %v", name, err)
+		}
+		if len(value.Value) < 20 {
+			t.Errorf("expected output %s to be a huge number, got %s", name, value.Value)
+		}
+	}
+	for name, count := range lower {
+		if count != 2 {
+			t.Errorf("expected output %s to be duplicated with a different case, got %d", name, count)
+		}
+	}
+
+	if _, err := ParseAnomalies("unicode-names,bogus"); err == nil {
+		t.Error("expected an unknown anomaly to be rejected")
+	}
+}
+
+func TestAnomalyValues(t *testing.T) {
+	state := roundTrip(t,
+		WithResources(100),
+		WithMultiInstanceChance(50),
+		WithMultiInstanceMax(3),
+		WithAnomalies(AnomalyUnicodeNames, AnomalyEscapedKeys, AnomalyDeepNesting, AnomalyHugeStrings),
+		WithAnomalyChance(100),
+	)
+
+	addresses := make(map[string]bool)
+	for _, resource := range state.Resources {
+		addresses[resourceAddress(resource)] = true
+	}
+
+	var dependencies, hugeStrings int
+	for _, resource := range state.Resources {
+		if !strings.ContainsFunc(resource.Name, func(r rune) bool { return r > unicode.MaxASCII }) {
+			t.Errorf("expected %s to have a non-ASCII name", resourceAddress(resource))
+		}
+		for _, instance := range resource.Instances {
+			address := instanceAddress(resourceAddress(resource), instance)
+			if len(resource.Instances) > 1 {
+				key, _ := instance.IndexKey.(string)
+				if !slices.ContainsFunc(escapedKeys, func(prefix string) bool { return strings.HasPrefix(key, prefix+" ") }) {
+					t.Errorf("expected %s to have an escaped key", address)
+				}
+			}
+			for _, dependency := range instance.Dependencies {
+				dependencies++
+				if !addresses[dependency] {
+					t.Errorf("expected dependency %s of %s to be in the state", dependency, address)
+				}
+			}
+
+			var attributes struct {
+				Nested   any    `json:"nested"`
+				UserData string `json:"user_data"`
+			}
+			if err := json.Unmarshal(instance.Attributes, &attributes); err != nil {
+				t.Fatalf("failed to decode attributes of %s: %v", address, err)
+			}
+			if depth := nestingDepth(attributes.Nested); depth < 100 {
+				t.Errorf("expected %s to have an attribute nested at least 100 levels deep, got %d", address, depth)
+			}
+			if len(attributes.UserData) >= 10_000_000 {
+				hugeStrings++
+			}
+		}
+	}
+	if dependencies == 0 {
+		t.Error("expected renamed resources to have dependencies")
+	}
+	if hugeStrings != 1 {
+		t.Errorf("expected a single huge string in the state, got %d", hugeStrings)
+	}
+
+	state = roundTrip(t,
+		WithResources(20),
+		WithOutputs(10),
+		WithAnomalies(AnomalyNullValues),
+		WithAnomalyChance(100),
+	)
+	for _, resource := range state.Resources {
+		for _, instance := range resource.Instances {
+			if string(instance.Attributes) != "null" {
+				t.Errorf("expected %s to have null attributes, got %s", instanceAddress(resourceAddress(resource), instance), instance.Attributes)
+			}
+		}
+	}
+	for name, output := range state.Outputs {
+		var value struct{ Value any }
+		if err := json.Unmarshal(output, &value); err != nil {
+			t.Fatalf("failed to decode output %s: %v", name, err)
+		}
+		if value.Value != nil {
+			t.Errorf("expected output %s to be null, got %v", name, value.Value)
+		}
+	}
+}
+
+// roundTrip generates a state and decodes it again, so that tests see what
+// a parser reading the state would
+func roundTrip(t *testing.T, opts ...Option) *StateV4 {
+	t.Helper()
+	state, err := NewFakeStateV4(opts...)
+	if err != nil {
+		t.Fatalf("failed to generate fake state: %v", err)
+	}
+	b, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("failed to marshal state: %v", err)
+	}
+	var decoded StateV4
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal state: %v", err)
+	}
+	return &decoded
+}
+
+// nestingDepth returns how many objects and arrays deep a value goes
+func nestingDepth(value any) int {
+	switch value := value.(type) {
+	case map[string]any:
+		depth := 0
+		for _, child := range value {
+			depth = max(depth, nestingDepth(child))
+		}
+		return depth + 1
+	case []any:
+		depth := 0
+		for _, child := range value {
+			depth = max(depth, nestingDepth(child))
+		}
+		return depth + 1
+	}
+	return 0
+}
@@ -0,0 +1,102 @@
+package statefaker
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+
+	"github.com/go-faker/faker/v4"
+)
+
+type CheckResultsV4 struct {
+	ObjectKind string                 `json:"object_kind"`
+	ConfigAddr string                 `json:"config_addr"`
+	Status     string                 `json:"status"`
+	Objects    []CheckResultsObjectV4 `json:"objects"`
+}
+
+type CheckResultsObjectV4 struct {
+	ObjectAddr      string   `json:"object_addr"`
+	Status          string   `json:"status"`
+	FailureMessages []string `json:"failure_messages,omitempty"`
+}
+
+// generateCheckResults generates the results of preconditions and
+// postconditions for some of the managed resources and outputs in a state
+func generateCheckResults(resources []ResourceV4, outputs []string) []CheckResultsV4 {
+	var results []CheckResultsV4
+
+	for _, resource := range resources {
+		// 1 in 10 managed resources declare conditions
+		if resource.Mode != "managed" || rand.IntN(10) != 0 {
+			continue
+		}
+
+		configAddr := fmt.Sprintf("%s.%s", resource.Type, resource.Name)
+		if resource.Module != "" {
+			configAddr = fmt.Sprintf("%s.%s", resource.Module, configAddr)
+		}
+
+		result := CheckResultsV4{
+			ObjectKind: "resource",
+			ConfigAddr: configAddr,
+			Status:     "pass",
+		}
+		for _, instance := range resource.Instances {
+			result.Objects = append(result.Objects, generateCheckResultsObject(configAddr+instanceKey(instance.IndexKey)))
+		}
+		result.Status = aggregateCheckStatus(result.Objects)
+
+		results = append(results, result)
+	}
+
+	for _, output := range outputs {
+		// 1 in 10 outputs declare preconditions
+		if rand.IntN(10) != 0 {
+			continue
+		}
+
+		configAddr := "output." + output
+		object := generateCheckResultsObject(configAddr)
+		results = append(results, CheckResultsV4{
+			ObjectKind: "output",
+			ConfigAddr: configAddr,
+			Status:     object.Status,
+			Objects:    []CheckResultsObjectV4{object},
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ConfigAddr < results[j].ConfigAddr
+	})
+
+	return results
+}
+
+func generateCheckResultsObject(objectAddr string) CheckResultsObjectV4 {
+	object := CheckResultsObjectV4{
+		ObjectAddr: objectAddr,
+		Status:     "pass",
+	}
+	// Conditions occasionally fail
+	if rand.IntN(20) == 0 {
+		object.Status = "fail"
+		object.FailureMessages = []string{strings.TrimSuffix(faker.Sentence(), ".")}
+	}
+	return object
+}
+
+// aggregateCheckStatus returns the status of a checkable object given the
+// statuses of all of its instances
+func aggregateCheckStatus(objects []CheckResultsObjectV4) string {
+	if len(objects) == 0 {
+		return "unknown"
+	}
+	for _, object := range objects {
+		if object.Status == "fail" {
+			return "fail"
+		}
+	}
+	return "pass"
+}
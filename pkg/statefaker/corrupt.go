@@ -0,0 +1,201 @@
+package statefaker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+)
+
+// Corruption is a named way of turning a valid state into an invalid one,
+// used to check that state readers reject bad state with a good error
+type Corruption struct {
+	Name        string
+	Description string
+	// ExpectedFailure describes how a correct state reader should reject the
+	// corrupted state
+	ExpectedFailure string
+	// RequiresState is set for corruptions that can only be seen by comparing
+	// the corrupted state with the state it was made from, so they are
+	// pointless on a freshly generated state
+	RequiresState bool
+
+	apply func(state map[string]any) error
+}
+
+// Corruptions returns every known corruption
+func Corruptions() []Corruption {
+	return []Corruption{
+		{
+			Name:            "wrong-version",
+			Description:     "sets the state format version to 5, which does not exist",
+			ExpectedFailure: "unsupported state format version 5",
+			apply: func(state map[string]any) error {
+				state["version"] = 5
+				return nil
+			},
+		},
+		{
+			Name:            "missing-lineage",
+			Description:     "removes the lineage",
+			ExpectedFailure: "the state has no lineage, so it cannot be matched to the states before it",
+			apply: func(state map[string]any) error {
+				delete(state, "lineage")
+				return nil
+			},
+		},
+		{
+			Name:            "duplicate-resource",
+			Description:     "repeats a resource so that two entries share the same address",
+			ExpectedFailure: "duplicate resource address in state",
+			apply: func(state map[string]any) error {
+				resources, err := corruptibleResources(state)
+				if err != nil {
+					return err
+				}
+				state["resources"] = append(resources, resources[rand.IntN(len(resources))])
+				return nil
+			},
+		},
+		{
+			Name:            "mismatched-index-keys",
+			Description:     "mixes number (count) and string (for_each) index keys within one resource",
+			ExpectedFailure: "a resource's instances must all use either count or for_each keys",
+			apply: func(state map[string]any) error {
+				resource, instances, err := resourceWithInstances(state)
+				if err != nil {
+					return err
+				}
+				// Make sure there are at least two instances to disagree
+				if len(instances) == 1 {
+					instances = append(instances, copyMap(instances[0].(map[string]any)))
+				}
+				instances[0].(map[string]any)["index_key"] = 0
+				instances[1].(map[string]any)["index_key"] = "zero"
+				resource["instances"] = instances
+				return nil
+			},
+		},
+		{
+			Name:            "truncated-json",
+			Description:     "cuts the JSON off part way through",
+			ExpectedFailure: "the state is not valid JSON (unexpected end of input)",
+		},
+		{
+			Name:            "invalid-provider",
+			Description:     "replaces a resource's provider with an address that cannot be parsed",
+			ExpectedFailure: "invalid provider configuration address",
+			apply: func(state map[string]any) error {
+				resources, err := corruptibleResources(state)
+				if err != nil {
+					return err
+				}
+				resource := resources[rand.IntN(len(resources))].(map[string]any)
+				resource["provider"] = []string{
+					`provider["registry.terraform.io/hashicorp/"]`,
+					`provider[registry.terraform.io/hashicorp/aws]`,
+					`provider["registry.terraform.io/hashicorp/aws"].`,
+					`aws`,
+				}[rand.IntN(4)]
+				return nil
+			},
+		},
+		{
+			Name:            "schema-version-regression",
+			Description:     "bumps the serial but lowers the schema_version of a resource's instances, or makes it negative when already 0",
+			ExpectedFailure: "resource instance schema_version went backwards (or cannot be negative)",
+			RequiresState:   true,
+			apply: func(state map[string]any) error {
+				_, instances, err := resourceWithInstances(state)
+				if err != nil {
+					return err
+				}
+				if serial, ok := state["serial"].(json.Number); ok {
+					n, _ := serial.Int64()
+					state["serial"] = n + 1
+				}
+				for _, instance := range instances {
+					instance := instance.(map[string]any)
+					version, _ := instance["schema_version"].(json.Number).Int64()
+					instance["schema_version"] = version - 1
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// Corrupt applies the named corruption to a valid state and returns the
+// resulting JSON. The state itself is not modified.
+func Corrupt(state *StateV4, name string) ([]byte, error) {
+	var corruption *Corruption
+	for _, c := range Corruptions() {
+		if c.Name == name {
+			corruption = &c
+			break
+		}
+	}
+	if corruption == nil {
+		return nil, fmt.Errorf("unknown corruption %q", name)
+	}
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	// Truncation is the one corruption that can't be expressed as JSON
+	if corruption.apply == nil {
+		return b[:rand.IntN(len(b)-1)+1], nil
+	}
+
+	// Work on a generic copy of the state so that corruptions can produce
+	// values the StateV4 types cannot hold
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var generic map[string]any
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, fmt.Errorf("failed to decode state: %w", err)
+	}
+
+	if err := corruption.apply(generic); err != nil {
+		return nil, fmt.Errorf("failed to apply %s: %w", name, err)
+	}
+
+	return json.Marshal(generic)
+}
+
+// corruptibleResources returns the resources of a generic state, or an
+// error if there are none to corrupt
+func corruptibleResources(state map[string]any) ([]any, error) {
+	resources, _ := state["resources"].([]any)
+	if len(resources) == 0 {
+		return nil, fmt.Errorf("state has no resources")
+	}
+	return resources, nil
+}
+
+// resourceWithInstances returns a random resource of a generic state that
+// has at least one instance, along with its instances, or an error if there
+// is none
+func resourceWithInstances(state map[string]any) (map[string]any, []any, error) {
+	resources, err := corruptibleResources(state)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, i := range rand.Perm(len(resources)) {
+		resource, _ := resources[i].(map[string]any)
+		if instances, _ := resource["instances"].([]any); len(instances) > 0 {
+			return resource, instances, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("state has no resource instances")
+}
+
+func copyMap(m map[string]any) map[string]any {
+	c := make(map[string]any, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,141 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestCorrupt(t *testing.T) {
+	state, err := NewFakeStateV4(WithResources(10))
+	if err != nil {
+		t.Fatalf("failed to generate fake state: %v", err)
+	}
+	original, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("failed to marshal state: %v", err)
+	}
+
+	for _, corruption := range Corruptions() {
+		t.Run(corruption.Name, func(t *testing.T) {
+			b, err := Corrupt(state, corruption.Name)
+			if err != nil {
+				t.Fatalf("failed to corrupt state: %v", err)
+			}
+
+			var corrupted StateV4
+			err = json.Unmarshal(b, &corrupted)
+			switch corruption.Name {
+			case "truncated-json", "mismatched-index-keys":
+				// These no longer fit the StateV4 types
+			case "schema-version-regression":
+				if !corruption.RequiresState {
+					t.Error("expected the regression to require the state it was made from")
+				}
+				assertSchemaVersionRegression(t, state, b)
+			case "wrong-version":
+				if corrupted.Version == 4 {
+					t.Error("expected the version to change")
+				}
+			case "missing-lineage":
+				if corrupted.Lineage != "" {
+					t.Error("expected the lineage to be removed")
+				}
+			case "duplicate-resource":
+				seen := make(map[string]bool)
+				duplicate := false
+				for _, r := range corrupted.Resources {
+					address := fmt.Sprintf("%s.%s.%s.%s", r.Module, r.Mode, r.Type, r.Name)
+					duplicate = duplicate || seen[address]
+					seen[address] = true
+				}
+				if !duplicate {
+					t.Error("expected a duplicate resource address")
+				}
+			}
+			if corruption.Name == "truncated-json" && err == nil {
+				t.Error("expected truncated JSON to fail to unmarshal")
+			}
+			if corruption.RequiresState && corruption.Name != "schema-version-regression" {
+				t.Error("expected only schema-version-regression to require a state")
+			}
+			if corruption.ExpectedFailure == "" {
+				t.Error("corruption does not document its expected failure")
+			}
+		})
+	}
+
+	after, _ := json.Marshal(state)
+	if string(after) != string(original) {
+		t.Error("Corrupt modified the state")
+	}
+
+	if _, err := Corrupt(state, "bogus"); err == nil {
+		t.Error("expected an unknown corruption to be rejected")
+	}
+}
+
+// assertSchemaVersionRegression checks that the corrupted state is a later
+// serial than the state it was made from, and that a resource's instances
+// all went back to an earlier schema version
+func assertSchemaVersionRegression(t *testing.T, state *StateV4, b []byte) {
+	t.Helper()
+	var corrupted struct {
+		Serial    int
+		Resources []struct {
+			Instances []struct {
+				SchemaVersion int `json:"schema_version"`
+			}
+		}
+	}
+	if err := json.Unmarshal(b, &corrupted); err != nil {
+		t.Fatalf("failed to decode corrupted state: %v", err)
+	}
+	if corrupted.Serial != state.Serial+1 {
+		t.Errorf("expected the serial to go from %d to %d, got %d", state.Serial, state.Serial+1, corrupted.Serial)
+	}
+
+	regressed := 0
+	for i, resource := range corrupted.Resources {
+		lowered := 0
+		for j, instance := range resource.Instances {
+			if instance.SchemaVersion < state.Resources[i].Instances[j].SchemaVersion {
+				lowered++
+			}
+		}
+		if lowered > 0 && lowered == len(resource.Instances) {
+			regressed++
+		}
+	}
+	if regressed != 1 {
+		t.Errorf("expected the instances of a single resource to go back a schema version, got %d resources", regressed)
+	}
+}
+
+func TestCorruptEmptyInstances(t *testing.T) {
+	// Most resources have no instances, as after the empty-instances anomaly
+	state := &StateV4{Version: 4, Serial: 1, Lineage: "lineage"}
+	for i := range 10 {
+		state.Resources = append(state.Resources, ResourceV4{Mode: "managed", Type: "aws_vpc", Name: fmt.Sprintf("empty_%d", i), Instances: []InstanceV4{}})
+	}
+	state.Resources = append(state.Resources, ResourceV4{Mode: "managed", Type: "aws_instance", Name: "web", Instances: []InstanceV4{
+		{SchemaVersion: 1, Attributes: json.RawMessage(`{"id":"i-1"}`)},
+	}})
+
+	for range 20 {
+		for _, name := range []string{"mismatched-index-keys", "schema-version-regression"} {
+			b, err := Corrupt(state, name)
+			if err != nil {
+				t.Fatalf("failed to apply %s: %v", name, err)
+			}
+			if name == "schema-version-regression" {
+				assertSchemaVersionRegression(t, state, b)
+			}
+		}
+	}
+
+	state.Resources = state.Resources[:10]
+	if _, err := Corrupt(state, "mismatched-index-keys"); err == nil {
+		t.Error("expected an error corrupting the instances of a state without any")
+	}
+}
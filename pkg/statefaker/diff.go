@@ -0,0 +1,392 @@
+package statefaker
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// ChangeAction describes how something differs between two states
+type ChangeAction string
+
+const (
+	// ChangeAdded is something only in the second state
+	ChangeAdded ChangeAction = "added"
+	// ChangeRemoved is something only in the first state
+	ChangeRemoved ChangeAction = "removed"
+	// ChangeChanged is something in both states that differs
+	ChangeChanged ChangeAction = "changed"
+)
+
+// StateDiff is the structural difference between two states
+type StateDiff struct {
+	Resources []ResourceChange `json:"resources"`
+	Outputs   []OutputChange   `json:"outputs"`
+}
+
+// ResourceChange is a resource that was added, removed or has a changed
+// provider or instances
+type ResourceChange struct {
+	Address   string           `json:"address"`
+	Action    ChangeAction     `json:"action"`
+	Fields    []FieldChange    `json:"fields,omitempty"`
+	Instances []InstanceChange `json:"instances,omitempty"`
+}
+
+// InstanceChange is a resource instance, or a deposed object, that was added,
+// removed or has changed attributes or other fields
+type InstanceChange struct {
+	Address    string            `json:"address"`
+	Action     ChangeAction      `json:"action"`
+	Fields     []FieldChange     `json:"fields,omitempty"`
+	Attributes []AttributeChange `json:"attributes,omitempty"`
+}
+
+// FieldChange is a field of a resource or instance other than its
+// attributes, such as its provider, schema version or identity, that changed.
+// Fields that are absent are null.
+type FieldChange struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// AttributeChange is a value within an instance's attributes that was added,
+// removed or changed. The path is empty when the attributes as a whole
+// changed, such as from null to an object. Object keys that are not
+// identifiers and list indexes are written in brackets, as in
+// tags["kubernetes.io/name"] or ports[0].
+type AttributeChange struct {
+	Path   string          `json:"path"`
+	Action ChangeAction    `json:"action"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// OutputChange is an output that was added, removed or changed
+type OutputChange struct {
+	Name   string          `json:"name"`
+	Action ChangeAction    `json:"action"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Empty reports whether the states had no differences
+func (d *StateDiff) Empty() bool {
+	return len(d.Resources) == 0 && len(d.Outputs) == 0
+}
+
+// Diff compares two states, matching resources and instances by address and
+// outputs by name. Besides attributes, it compares the provider of each
+// resource and the schema version, identity, private data, dependencies and
+// other fields of each instance. Differences in serial, lineage and other
+// state metadata are ignored.
+func Diff(a, b *StateV4) (*StateDiff, error) {
+	diff := &StateDiff{
+		Resources: []ResourceChange{},
+		Outputs:   []OutputChange{},
+	}
+
+	before := resourcesByAddress(a)
+	after := resourcesByAddress(b)
+	for _, address := range slices.Sorted(maps.Keys(before)) {
+		if _, ok := after[address]; !ok {
+			diff.Resources = append(diff.Resources, ResourceChange{Address: address, Action: ChangeRemoved})
+		}
+	}
+	for _, address := range slices.Sorted(maps.Keys(after)) {
+		resource, ok := before[address]
+		if !ok {
+			diff.Resources = append(diff.Resources, ResourceChange{Address: address, Action: ChangeAdded})
+			continue
+		}
+		fields := diffFields(resourceFields(resource), resourceFields(after[address]))
+		instances, err := diffInstances(address, resource, after[address])
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) > 0 || len(instances) > 0 {
+			diff.Resources = append(diff.Resources, ResourceChange{Address: address, Action: ChangeChanged, Fields: fields, Instances: instances})
+		}
+	}
+	slices.SortStableFunc(diff.Resources, func(x, y ResourceChange) int {
+		return cmp.Compare(x.Address, y.Address)
+	})
+
+	for _, name := range slices.Sorted(maps.Keys(a.Outputs)) {
+		if _, ok := b.Outputs[name]; !ok {
+			diff.Outputs = append(diff.Outputs, OutputChange{Name: name, Action: ChangeRemoved, Before: a.Outputs[name]})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(b.Outputs)) {
+		output, ok := a.Outputs[name]
+		switch {
+		case !ok:
+			diff.Outputs = append(diff.Outputs, OutputChange{Name: name, Action: ChangeAdded, After: b.Outputs[name]})
+		case !equalJSON(output, b.Outputs[name]):
+			diff.Outputs = append(diff.Outputs, OutputChange{Name: name, Action: ChangeChanged, Before: output, After: b.Outputs[name]})
+		}
+	}
+	slices.SortStableFunc(diff.Outputs, func(x, y OutputChange) int {
+		return cmp.Compare(x.Name, y.Name)
+	})
+
+	return diff, nil
+}
+
+func resourcesByAddress(state *StateV4) map[string]ResourceV4 {
+	resources := make(map[string]ResourceV4, len(state.Resources))
+	for _, resource := range state.Resources {
+		resources[resourceAddress(resource)] = resource
+	}
+	return resources
+}
+
+// instanceAddress returns the absolute address of a resource instance
+func instanceAddress(resourceAddress string, instance InstanceV4) string {
+	return resourceAddress + instanceKey(instance.IndexKey)
+}
+
+// instanceKey returns an index key the way it is written in an address:
+// [0] for count, ["key"] for for_each and nothing when there is no key
+func instanceKey(key any) string {
+	switch key := key.(type) {
+	case nil:
+		return ""
+	case string:
+		return fmt.Sprintf("[%q]", key)
+	case float64:
+		return "[" + strconv.FormatFloat(key, 'f', -1, 64) + "]"
+	default:
+		return fmt.Sprintf("[%v]", key)
+	}
+}
+
+// countIndex returns the index of an instance of a resource using count
+func countIndex(key any) (int, bool) {
+	switch key := key.(type) {
+	case int:
+		return key, true
+	case float64:
+		return int(key), true
+	case json.Number:
+		i, err := key.Int64()
+		return int(i), err == nil
+	}
+	return 0, false
+}
+
+// objectAddress returns the address of an instance, followed by the key of
+// the object for deposed objects, the way terraform shows them
+func objectAddress(resourceAddress string, instance InstanceV4) string {
+	address := instanceAddress(resourceAddress, instance)
+	if instance.Deposed != "" {
+		address += fmt.Sprintf(" (deposed object %s)", instance.Deposed)
+	}
+	return address
+}
+
+// resourceFields returns the fields of a resource compared by Diff, other
+// than its address and instances
+func resourceFields(resource ResourceV4) map[string]any {
+	return map[string]any{
+		"provider": resource.Provider,
+		"each":     resource.Each,
+	}
+}
+
+// instanceFields returns the fields of an instance compared by Diff, other
+// than its address and attributes
+func instanceFields(instance InstanceV4) map[string]any {
+	return map[string]any{
+		"status":                  instance.Status,
+		"schema_version":          instance.SchemaVersion,
+		"attributes_flat":         instance.AttributesFlat,
+		"sensitive_attributes":    instance.SensitiveAttributes,
+		"identity_schema_version": instance.IdentitySchemaVersion,
+		"identity":                instance.Identity,
+		"private":                 instance.Private,
+		"dependencies":            instance.Dependencies,
+		"create_before_destroy":   instance.CreateBeforeDestroy,
+	}
+}
+
+// diffFields returns the fields whose values differ, by name. Empty values
+// are the same as absent ones, as they are when terraform writes them.
+func diffFields(a, b map[string]any) []FieldChange {
+	normalize := func(value any) json.RawMessage {
+		b := encodeValue(value)
+		switch string(b) {
+		case `""`, "false", "[]", "{}":
+			return json.RawMessage("null")
+		}
+		return b
+	}
+
+	var changes []FieldChange
+	for _, field := range slices.Sorted(maps.Keys(a)) {
+		before, after := normalize(a[field]), normalize(b[field])
+		if !equalJSON(before, after) {
+			changes = append(changes, FieldChange{Field: field, Before: before, After: after})
+		}
+	}
+	return changes
+}
+
+func diffInstances(address string, a, b ResourceV4) ([]InstanceChange, error) {
+	before := make(map[string]InstanceV4, len(a.Instances))
+	for _, instance := range a.Instances {
+		before[objectAddress(address, instance)] = instance
+	}
+	after := make(map[string]InstanceV4, len(b.Instances))
+	for _, instance := range b.Instances {
+		after[objectAddress(address, instance)] = instance
+	}
+
+	var changes []InstanceChange
+	for _, address := range slices.Sorted(maps.Keys(before)) {
+		if _, ok := after[address]; !ok {
+			changes = append(changes, InstanceChange{Address: address, Action: ChangeRemoved})
+		}
+	}
+	for _, address := range slices.Sorted(maps.Keys(after)) {
+		instance, ok := before[address]
+		if !ok {
+			changes = append(changes, InstanceChange{Address: address, Action: ChangeAdded})
+			continue
+		}
+		fields := diffFields(instanceFields(instance), instanceFields(after[address]))
+		attributes, err := diffAttributes(instance.Attributes, after[address].Attributes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s: %w", address, err)
+		}
+		if len(fields) > 0 || len(attributes) > 0 {
+			changes = append(changes, InstanceChange{Address: address, Action: ChangeChanged, Fields: fields, Attributes: attributes})
+		}
+	}
+	slices.SortStableFunc(changes, func(x, y InstanceChange) int {
+		return cmp.Compare(x.Address, y.Address)
+	})
+	return changes, nil
+}
+
+func diffAttributes(a, b json.RawMessage) ([]AttributeChange, error) {
+	before, err := decodeValue(a)
+	if err != nil {
+		return nil, err
+	}
+	after, err := decodeValue(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []AttributeChange
+	diffValues("", before, after, &changes)
+	return changes, nil
+}
+
+// diffValues appends the differences between two decoded JSON values,
+// descending into objects and arrays that exist on both sides
+func diffValues(path string, a, b any, changes *[]AttributeChange) {
+	switch a := a.(type) {
+	case map[string]any:
+		if b, ok := b.(map[string]any); ok {
+			for _, key := range slices.Sorted(maps.Keys(a)) {
+				if _, ok := b[key]; !ok {
+					*changes = append(*changes, AttributeChange{Path: joinPath(path, key), Action: ChangeRemoved, Before: encodeValue(a[key])})
+				}
+			}
+			for _, key := range slices.Sorted(maps.Keys(b)) {
+				if _, ok := a[key]; !ok {
+					*changes = append(*changes, AttributeChange{Path: joinPath(path, key), Action: ChangeAdded, After: encodeValue(b[key])})
+					continue
+				}
+				diffValues(joinPath(path, key), a[key], b[key], changes)
+			}
+			return
+		}
+	case []any:
+		if b, ok := b.([]any); ok {
+			for i := range max(len(a), len(b)) {
+				element := path + "[" + strconv.Itoa(i) + "]"
+				switch {
+				case i >= len(b):
+					*changes = append(*changes, AttributeChange{Path: element, Action: ChangeRemoved, Before: encodeValue(a[i])})
+				case i >= len(a):
+					*changes = append(*changes, AttributeChange{Path: element, Action: ChangeAdded, After: encodeValue(b[i])})
+				default:
+					diffValues(element, a[i], b[i], changes)
+				}
+			}
+			return
+		}
+	}
+
+	before, after := encodeValue(a), encodeValue(b)
+	if !bytes.Equal(before, after) {
+		*changes = append(*changes, AttributeChange{Path: path, Action: ChangeChanged, Before: before, After: after})
+	}
+}
+
+// joinPath appends an object key to an attribute path. Keys that are not
+// identifiers, such as the tag key kubernetes.io/name, are written as a
+// quoted index like tags["kubernetes.io/name"] so that paths are unambiguous.
+func joinPath(path, key string) string {
+	if !isIdentifier(key) {
+		return path + "[" + strconv.Quote(key) + "]"
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// isIdentifier reports whether s is a valid terraform identifier
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r == '-' || r >= '0' && r <= '9'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// decodeValue decodes JSON preserving the precision of numbers
+func decodeValue(b json.RawMessage) (any, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func encodeValue(value any) json.RawMessage {
+	// Values came from decoding JSON, so they always encode
+	b, _ := json.Marshal(value)
+	return b
+}
+
+// equalJSON reports whether two JSON documents are semantically equal
+func equalJSON(a, b json.RawMessage) bool {
+	x, errX := decodeValue(a)
+	y, errY := decodeValue(b)
+	if errX != nil || errY != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(encodeValue(x), encodeValue(y))
+}
@@ -0,0 +1,183 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	resource := func(module, name string, instances ...InstanceV4) ResourceV4 {
+		return ResourceV4{Module: module, Mode: "managed", Type: "aws_instance", Name: name, Instances: instances}
+	}
+	instance := func(key, attributes string) InstanceV4 {
+		return InstanceV4{IndexKey: key, Attributes: json.RawMessage(attributes)}
+	}
+
+	a := &StateV4{
+		Version: 4,
+		Serial:  1,
+		Resources: []ResourceV4{
+			resource("", "web", instance("a", `{"id":"i-1","tags":{"env":"dev"},"ports":[80,443]}`), instance("b", `{"id":"i-2"}`)),
+			resource("module.old", "worker", instance("", `{"id":"i-3"}`)),
+		},
+		Outputs: map[string]json.RawMessage{
+			"kept":    json.RawMessage(`{"value":"same","type":"string"}`),
+			"changed": json.RawMessage(`{"value":"before","type":"string"}`),
+			"removed": json.RawMessage(`{"value":"gone","type":"string"}`),
+		},
+	}
+	b := &StateV4{
+		Version: 4,
+		Serial:  2,
+		Resources: []ResourceV4{
+			resource("", "web", instance("a", `{"id":"i-1","tags":{"env":"prod","team":"web"},"ports":[80]}`), instance("c", `{"id":"i-4"}`)),
+			resource("module.new", "worker", instance("", `{"id":"i-3"}`)),
+		},
+		Outputs: map[string]json.RawMessage{
+			"kept":    json.RawMessage(`{"type":"string","value":"same"}`),
+			"changed": json.RawMessage(`{"value":"after","type":"string"}`),
+			"added":   json.RawMessage(`{"value":"new","type":"string"}`),
+		},
+	}
+
+	diff, err := Diff(a, b)
+	if err != nil {
+		t.Fatalf("failed to diff states: %v", err)
+	}
+
+	expectedResources := []ResourceChange{
+		{Address: "aws_instance.web", Action: ChangeChanged, Instances: []InstanceChange{
+			{Address: `aws_instance.web["a"]`, Action: ChangeChanged, Attributes: []AttributeChange{
+				{Path: "ports[1]", Action: ChangeRemoved, Before: json.RawMessage(`443`)},
+				{Path: "tags.env", Action: ChangeChanged, Before: json.RawMessage(`"dev"`), After: json.RawMessage(`"prod"`)},
+				{Path: "tags.team", Action: ChangeAdded, After: json.RawMessage(`"web"`)},
+			}},
+			{Address: `aws_instance.web["b"]`, Action: ChangeRemoved},
+			{Address: `aws_instance.web["c"]`, Action: ChangeAdded},
+		}},
+		{Address: "module.new.aws_instance.worker", Action: ChangeAdded},
+		{Address: "module.old.aws_instance.worker", Action: ChangeRemoved},
+	}
+	if got, expected := mustMarshal(t, diff.Resources), mustMarshal(t, expectedResources); got != expected {
+		t.Errorf("unexpected resource changes:\n got: %s\nwant: %s", got, expected)
+	}
+
+	var outputs []string
+	for _, output := range diff.Outputs {
+		outputs = append(outputs, output.Name+" "+string(output.Action))
+	}
+	if got, expected := mustMarshal(t, outputs), `["added added","changed changed","removed removed"]`; got != expected {
+		t.Errorf("unexpected output changes: %s", got)
+	}
+
+	same, err := Diff(a, a)
+	if err != nil {
+		t.Fatalf("failed to diff states: %v", err)
+	}
+	if !same.Empty() {
+		t.Errorf("expected no differences between a state and itself, got %s", mustMarshal(t, same))
+	}
+}
+
+func mustMarshal(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	return string(b)
+}
+
+func TestDiffFields(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/count.tfstate")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	decode := func() *StateV4 {
+		var state StateV4
+		if err := json.Unmarshal(fixture, &state); err != nil {
+			t.Fatalf("failed to decode fixture: %v", err)
+		}
+		return &state
+	}
+	identitySchemaVersion := 0
+
+	for _, tc := range []struct {
+		name     string
+		change   func(state *StateV4)
+		expected string
+	}{
+		{
+			name: "aliased provider",
+			change: func(state *StateV4) {
+				state.Resources[1].Provider = `provider["registry.terraform.io/hashicorp/aws"].west`
+			},
+			expected: `[{"address":"aws_s3_bucket.logs","action":"changed","fields":[{"field":"provider","before":"provider[\"registry.terraform.io/hashicorp/aws\"]","after":"provider[\"registry.terraform.io/hashicorp/aws\"].west"}]}]`,
+		},
+		{
+			name: "schema upgrade",
+			change: func(state *StateV4) {
+				state.Resources[0].Instances[3].SchemaVersion = 2
+			},
+			expected: `[{"address":"aws_instance.web","action":"changed","instances":[{"address":"aws_instance.web[2]","action":"changed","fields":[{"field":"schema_version","before":1,"after":2}]}]}]`,
+		},
+		{
+			name: "identity and private data",
+			change: func(state *StateV4) {
+				instance := &state.Resources[0].Instances[0]
+				instance.IdentitySchemaVersion = &identitySchemaVersion
+				instance.Identity = json.RawMessage(`{"id":"i-0a1b2c3d4e5f60718"}`)
+				instance.Private = ""
+			},
+			expected: `[{"address":"aws_instance.web","action":"changed","instances":[{"address":"aws_instance.web[0]","action":"changed","fields":[{"field":"identity","before":null,"after":{"id":"i-0a1b2c3d4e5f60718"}},{"field":"identity_schema_version","before":null,"after":0},{"field":"private","before":"eyJlMmJmYjczMC1lY2FhLTExZTYtOGY4OC0zNDM2M2JjN2M0YzAiOnsiY3JlYXRlIjo2MDAwMDAwMDAwMDB9LCJzY2hlbWFfdmVyc2lvbiI6IjEifQ==","after":null}]}]}]`,
+		},
+		{
+			name: "dependencies and sensitive attributes",
+			change: func(state *StateV4) {
+				instance := &state.Resources[2].Instances[0]
+				instance.Dependencies = nil
+				instance.SensitiveAttributes = json.RawMessage(`[[{"type":"get_attr","value":"cidr_block"}]]`)
+			},
+			expected: `[{"address":"module.network.aws_subnet.private","action":"changed","instances":[{"address":"module.network.aws_subnet.private[\"a\"]","action":"changed","fields":[{"field":"dependencies","before":["aws_instance.web"],"after":null},{"field":"sensitive_attributes","before":null,"after":[[{"type":"get_attr","value":"cidr_block"}]]}]}]}]`,
+		},
+		{
+			name: "untainted and deposed object destroyed",
+			change: func(state *StateV4) {
+				state.Resources[0].Instances[2].Status = ""
+				state.Resources[0].Instances = append(state.Resources[0].Instances[:1], state.Resources[0].Instances[2:]...)
+			},
+			expected: `[{"address":"aws_instance.web","action":"changed","instances":[{"address":"aws_instance.web[0] (deposed object 6f1e3c2a)","action":"removed"},{"address":"aws_instance.web[1]","action":"changed","fields":[{"field":"status","before":"tainted","after":null}]}]}]`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := decode()
+			tc.change(b)
+			diff, err := Diff(decode(), b)
+			if err != nil {
+				t.Fatalf("failed to diff states: %v", err)
+			}
+			if got := mustMarshal(t, diff.Resources); got != tc.expected {
+				t.Errorf("unexpected resource changes:\n got: %s\nwant: %s", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDiffAttributePaths(t *testing.T) {
+	changes, err := diffAttributes(
+		json.RawMessage(`{"tags":{"kubernetes.io/name":"web","env":"dev"},"2fa":true}`),
+		json.RawMessage(`{"tags":{"kubernetes.io/name":"api","env":"prod"},"2fa":false}`),
+	)
+	if err != nil {
+		t.Fatalf("failed to diff attributes: %v", err)
+	}
+
+	var paths []string
+	for _, change := range changes {
+		paths = append(paths, change.Path)
+	}
+	if got, expected := mustMarshal(t, paths), `["[\"2fa\"]","tags.env","tags[\"kubernetes.io/name\"]"]`; got != expected {
+		t.Errorf("unexpected paths:\n got: %s\nwant: %s", got, expected)
+	}
+}
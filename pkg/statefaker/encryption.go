@@ -0,0 +1,153 @@
+package statefaker
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// The defaults used by OpenTofu's pbkdf2 key provider
+const (
+	openTofuEncryptionVersion = "v0"
+	openTofuMinPassphrase     = 16
+	openTofuPBKDF2Iterations  = 600000
+	openTofuPBKDF2SaltLength  = 32
+	openTofuPBKDF2KeyLength   = 32
+	openTofuPBKDF2Hash        = "sha512"
+)
+
+// OpenTofuEnvelope is the envelope OpenTofu writes in place of the state
+// when state encryption is configured. Meta holds the metadata each key
+// provider needs to derive its key again, keyed by the key provider address.
+type OpenTofuEnvelope struct {
+	Meta          map[string][]byte `json:"meta"`
+	EncryptedData []byte            `json:"encrypted_data"`
+	Version       string            `json:"encryption_version"`
+}
+
+// openTofuPBKDF2Meta is the metadata stored by the pbkdf2 key provider
+type openTofuPBKDF2Meta struct {
+	Salt         []byte `json:"salt"`
+	Iterations   int    `json:"iterations"`
+	HashFunction string `json:"hash_function"`
+	KeyLength    int    `json:"key_length"`
+}
+
+// EncryptOpenTofu encrypts a marshaled state the way OpenTofu does when it is
+// configured with a pbkdf2 key provider and the aes_gcm method:
+//
+//	key_provider "pbkdf2" "<keyProvider>" {
+//	  passphrase = "<passphrase>"
+//	}
+//	method "aes_gcm" "..." {
+//	  keys = key_provider.pbkdf2.<keyProvider>
+//	}
+func EncryptOpenTofu(state []byte, passphrase, keyProvider string) ([]byte, error) {
+	if len(passphrase) < openTofuMinPassphrase {
+		return nil, fmt.Errorf("passphrase must be at least %d characters long", openTofuMinPassphrase)
+	}
+
+	meta := openTofuPBKDF2Meta{
+		Salt:         make([]byte, openTofuPBKDF2SaltLength),
+		Iterations:   openTofuPBKDF2Iterations,
+		HashFunction: openTofuPBKDF2Hash,
+		KeyLength:    openTofuPBKDF2KeyLength,
+	}
+	if _, err := rand.Read(meta.Salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := openTofuCipher(passphrase, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	// The nonce is prepended to the ciphertext
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(OpenTofuEnvelope{
+		Meta: map[string][]byte{
+			"key_provider.pbkdf2." + keyProvider: metaJSON,
+		},
+		EncryptedData: gcm.Seal(nonce, nonce, state, nil),
+		Version:       openTofuEncryptionVersion,
+	})
+}
+
+// DecryptOpenTofu decrypts a state encrypted by EncryptOpenTofu, or by
+// OpenTofu itself using a pbkdf2 key provider and the aes_gcm method
+func DecryptOpenTofu(encrypted []byte, passphrase, keyProvider string) ([]byte, error) {
+	var envelope OpenTofuEnvelope
+	if err := json.Unmarshal(encrypted, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal encrypted state: %w", err)
+	}
+	if envelope.Version == "" {
+		return nil, errors.New("state is not encrypted")
+	}
+	if envelope.Version != openTofuEncryptionVersion {
+		return nil, fmt.Errorf("unsupported encryption version %q", envelope.Version)
+	}
+
+	metaJSON, ok := envelope.Meta["key_provider.pbkdf2."+keyProvider]
+	if !ok {
+		return nil, fmt.Errorf("state has no metadata for key provider %q", keyProvider)
+	}
+	var meta openTofuPBKDF2Meta
+	if err := json.Unmarshal(metaJSON, &meta); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal key provider metadata: %w", err)
+	}
+
+	gcm, err := openTofuCipher(passphrase, meta)
+	if err != nil {
+		return nil, err
+	}
+	if len(envelope.EncryptedData) < gcm.NonceSize() {
+		return nil, errors.New("encrypted data is too short")
+	}
+
+	nonce, ciphertext := envelope.EncryptedData[:gcm.NonceSize()], envelope.EncryptedData[gcm.NonceSize():]
+	state, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt state: %w", err)
+	}
+	return state, nil
+}
+
+// openTofuCipher derives the AES-GCM cipher described by the key provider metadata
+func openTofuCipher(passphrase string, meta openTofuPBKDF2Meta) (cipher.AEAD, error) {
+	var h func() hash.Hash
+	switch meta.HashFunction {
+	case "sha256":
+		h = sha256.New
+	case "sha512":
+		h = sha512.New
+	default:
+		return nil, fmt.Errorf("unsupported hash function %q", meta.HashFunction)
+	}
+
+	key, err := pbkdf2.Key(h, passphrase, meta.Salt, meta.Iterations, meta.KeyLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,48 @@
+package statefaker
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestEncryptOpenTofu(t *testing.T) {
+	state := []byte(`{"version":4,"terraform_version":"1.8.0","serial":1}`)
+	passphrase := "correct horse battery staple"
+
+	encrypted, err := EncryptOpenTofu(state, passphrase, "mykey")
+	if err != nil {
+		t.Fatalf("failed to encrypt state: %v", err)
+	}
+
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(encrypted, &envelope); err != nil {
+		t.Fatalf("failed to unmarshal envelope: %v", err)
+	}
+	for _, key := range []string{"meta", "encrypted_data", "encryption_version"} {
+		if _, ok := envelope[key]; !ok {
+			t.Errorf("envelope is missing %q", key)
+		}
+	}
+	if bytes.Contains(encrypted, []byte("terraform_version")) {
+		t.Error("envelope contains the plaintext state")
+	}
+
+	decrypted, err := DecryptOpenTofu(encrypted, passphrase, "mykey")
+	if err != nil {
+		t.Fatalf("failed to decrypt state: %v", err)
+	}
+	if !bytes.Equal(decrypted, state) {
+		t.Errorf("decrypted state %q does not match %q", decrypted, state)
+	}
+
+	if _, err := DecryptOpenTofu(encrypted, "not the right passphrase", "mykey"); err == nil {
+		t.Error("expected decrypting with the wrong passphrase to fail")
+	}
+	if _, err := DecryptOpenTofu(state, passphrase, "mykey"); err == nil {
+		t.Error("expected decrypting an unencrypted state to fail")
+	}
+	if _, err := EncryptOpenTofu(state, "too short", "mykey"); err == nil {
+		t.Error("expected a short passphrase to be rejected")
+	}
+}
@@ -0,0 +1,142 @@
+package statefaker
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// MarshalTerraform marshals a state exactly the way terraform writes it to
+// disk: resources and their instances sorted by address, two space
+// indentation, keys of attributes and other values sorted, check_results
+// written by terraform 1.5 and later even when there are none, and a
+// trailing newline. Fields terraform never writes, such as source, are left
+// out. The state itself is not modified.
+func MarshalTerraform(state *StateV4) ([]byte, error) {
+	sorted := *state
+	sorted.Source = ""
+	sorted.Resources = make([]ResourceV4, len(state.Resources))
+	copy(sorted.Resources, state.Resources)
+
+	for i, resource := range sorted.Resources {
+		instances := make([]InstanceV4, len(resource.Instances))
+		copy(instances, resource.Instances)
+		for j := range instances {
+			for _, raw := range []*json.RawMessage{&instances[j].Attributes, &instances[j].SensitiveAttributes, &instances[j].Identity} {
+				canonical, err := canonicalJSON(*raw)
+				if err != nil {
+					return nil, fmt.Errorf("failed to encode %s: %w", instanceAddress(resourceAddress(resource), instances[j]), err)
+				}
+				*raw = canonical
+			}
+		}
+		slices.SortStableFunc(instances, compareInstances)
+		sorted.Resources[i].Instances = instances
+	}
+
+	sorted.Outputs = make(map[string]json.RawMessage, len(state.Outputs))
+	for name, raw := range state.Outputs {
+		var output OutputV4
+		if err := json.Unmarshal(raw, &output); err != nil {
+			return nil, fmt.Errorf("failed to decode output %s: %w", name, err)
+		}
+		var err error
+		if output.Value, err = canonicalJSON(output.Value); err != nil {
+			return nil, fmt.Errorf("failed to encode output %s: %w", name, err)
+		}
+		if output.Type, err = canonicalJSON(output.Type); err != nil {
+			return nil, fmt.Errorf("failed to encode output %s: %w", name, err)
+		}
+		if sorted.Outputs[name], err = json.Marshal(output); err != nil {
+			return nil, fmt.Errorf("failed to encode output %s: %w", name, err)
+		}
+	}
+
+	if len(state.CheckResults) > 0 {
+		var checkResults []CheckResultsV4
+		if err := json.Unmarshal(state.CheckResults, &checkResults); err != nil {
+			return nil, fmt.Errorf("failed to decode check results: %w", err)
+		}
+		b, err := json.Marshal(checkResults)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode check results: %w", err)
+		}
+		sorted.CheckResults = b
+	} else if version, err := parseTerraformVersion(state.TerraformVersion); err == nil && version.supportsCheckResults() {
+		sorted.CheckResults = json.RawMessage("null")
+	}
+
+	// Terraform orders resources by module, then mode, type and name
+	sort.SliceStable(sorted.Resources, func(i, j int) bool {
+		a, b := sorted.Resources[i], sorted.Resources[j]
+		switch {
+		case a.Module != b.Module:
+			return a.Module < b.Module
+		case a.Mode != b.Mode:
+			return a.Mode < b.Mode
+		case a.Type != b.Type:
+			return a.Type < b.Type
+		default:
+			return a.Name < b.Name
+		}
+	})
+
+	b, err := json.MarshalIndent(&sorted, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// canonicalJSON re-encodes JSON with the keys of objects sorted, the way
+// terraform encodes values. Numbers keep their precision.
+func canonicalJSON(raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 {
+		return raw, nil
+	}
+	value, err := decodeValue(raw)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// compareInstances orders instances the way terraform does: count indexes
+// numerically before for_each keys, with each current object before its
+// deposed objects
+func compareInstances(a, b InstanceV4) int {
+	if c := compareIndexKeys(a.IndexKey, b.IndexKey); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Deposed, b.Deposed)
+}
+
+func compareIndexKeys(a, b any) int {
+	i, aCount := countIndex(a)
+	j, bCount := countIndex(b)
+	switch {
+	case aCount && bCount:
+		return cmp.Compare(i, j)
+	case aCount != bCount:
+		// A count index sorts first
+		if aCount {
+			return -1
+		}
+		return 1
+	}
+	x, _ := a.(string)
+	y, _ := b.(string)
+	return cmp.Compare(x, y)
+}
+
+// MarshalTerraformV3 marshals a legacy state the way terraform 0.11 and
+// earlier wrote it to disk, with four space indentation and a trailing newline
+func MarshalTerraformV3(state *StateV3) ([]byte, error) {
+	b, err := json.MarshalIndent(state, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
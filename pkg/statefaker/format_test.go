@@ -0,0 +1,107 @@
+package statefaker
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestMarshalTerraform(t *testing.T) {
+	state := &StateV4{
+		Version:          4,
+		TerraformVersion: "1.13.2",
+		Serial:           1,
+		Lineage:          "00000000-0000-0000-0000-000000000000",
+		Outputs:          map[string]json.RawMessage{},
+		Source:           "statefaker",
+		Resources: []ResourceV4{
+			{Module: "module.b", Mode: "managed", Type: "aws_vpc", Name: "main"},
+			{Mode: "managed", Type: "aws_vpc", Name: "main", Instances: []InstanceV4{
+				{IndexKey: "west", Attributes: json.RawMessage(`{"id":"vpc-2","cidr_block":"10.1.0.0/16"}`)},
+				{IndexKey: "east", Attributes: json.RawMessage(`{"id":"vpc-1"}`)},
+			}},
+			{Mode: "data", Type: "aws_iam_user", Name: "admin"},
+		},
+	}
+
+	b, err := MarshalTerraform(state)
+	if err != nil {
+		t.Fatalf("failed to marshal state: %v", err)
+	}
+
+	if !bytes.HasSuffix(b, []byte("}\n")) {
+		t.Error("expected a trailing newline")
+	}
+	// Raw attributes are reindented along with the rest of the state
+	if !bytes.Contains(b, []byte("\n          \"attributes\": {\n            \"id\": \"vpc-1\"\n          }\n")) {
+		t.Errorf("attributes were not indented like terraform:\n%s", b)
+	}
+	// Their keys are sorted, as terraform encodes them
+	if !bytes.Contains(b, []byte("\"attributes\": {\n            \"cidr_block\": \"10.1.0.0/16\",\n            \"id\": \"vpc-2\"\n")) {
+		t.Errorf("attribute keys were not sorted like terraform:\n%s", b)
+	}
+	// Terraform 1.5 and later write check_results even when there are none,
+	// and never write source
+	if !bytes.HasSuffix(b, []byte("  \"check_results\": null\n}\n")) {
+		t.Errorf("expected check_results to be written as null:\n%s", b)
+	}
+	if bytes.Contains(b, []byte(`"source"`)) {
+		t.Errorf("expected no source:\n%s", b)
+	}
+
+	var sorted StateV4
+	if err := json.Unmarshal(b, &sorted); err != nil {
+		t.Fatalf("failed to unmarshal state: %v", err)
+	}
+	var order []string
+	for _, resource := range sorted.Resources {
+		order = append(order, resource.Module+"/"+resource.Mode+"."+resource.Type)
+	}
+	expected := []string{"/data.aws_iam_user", "/managed.aws_vpc", "module.b/managed.aws_vpc"}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("unexpected resource order %v", order)
+		}
+	}
+	if sorted.Resources[1].Instances[0].IndexKey != "east" {
+		t.Error("expected instances to be sorted by index key")
+	}
+
+	// The original state is left untouched
+	if state.Resources[0].Module != "module.b" || state.Resources[1].Instances[0].IndexKey != "west" {
+		t.Error("MarshalTerraform modified the state")
+	}
+}
+
+func TestMarshalTerraformFixture(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/count.tfstate")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var state StateV4
+	if err := json.Unmarshal(fixture, &state); err != nil {
+		t.Fatalf("failed to decode fixture: %v", err)
+	}
+
+	// Scramble everything terraform orders
+	slices.Reverse(state.Resources)
+	for _, resource := range state.Resources {
+		slices.Reverse(resource.Instances)
+	}
+	for i, resource := range state.Resources {
+		if resource.Type == "aws_subnet" {
+			state.Resources[i].Instances[0].Attributes = json.RawMessage(`{"vpc_id":"vpc-0123456789abcdef0","id":"subnet-0123456789abcdef0","cidr_block":"10.0.1.0/24","availability_zone":"us-east-1a","arn":"arn:aws:ec2:us-east-1:123456789012:subnet/subnet-0123456789abcdef0"}`)
+		}
+	}
+	state.Outputs["web_ids"] = json.RawMessage(`{"type":["tuple",["string","string","string"]],"value":["i-0a1b2c3d4e5f60718","i-0b2c3d4e5f6071829","i-0c3d4e5f607182930"]}`)
+
+	b, err := MarshalTerraform(&state)
+	if err != nil {
+		t.Fatalf("failed to marshal state: %v", err)
+	}
+	if !bytes.Equal(b, fixture) {
+		t.Errorf("expected the state to be written exactly as terraform wrote it, got:\n%s", b)
+	}
+}
@@ -0,0 +1,124 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+)
+
+// sensitiveAttributeNames are the top level attributes that providers mark
+// as sensitive in their schemas
+var sensitiveAttributeNames = []string{
+	"password", "master_password", "secret", "private_key", "token", "kube_config",
+	"primary_access_key", "primary_connection_string", "custom_data",
+}
+
+// generator holds everything that is shared while generating a single state
+type generator struct {
+	options    Options
+	version    terraformVersion
+	providers  *providerRegistry
+	locations  *locationRegistry
+	references *referencePool
+	tagger     *tagger
+	// Resource names are unique within the whole state, which also makes
+	// them unique within each module and resource type
+	resourceNames *nameSequence
+	outputNames   *nameSequence
+	cloudNames    *nameSequence
+	modules       []string
+}
+
+func newGenerator(options Options) (*generator, error) {
+	if options.TerraformVersion == "" {
+		options.TerraformVersion = DefaultTerraformVersionV4
+	}
+	version, err := parseTerraformVersion(options.TerraformVersion)
+	if err != nil {
+		return nil, err
+	}
+	if !version.supportsStateV4() {
+		return nil, fmt.Errorf("terraform %s predates state format version 4, which requires terraform 0.12 or later", options.TerraformVersion)
+	}
+
+	return &generator{
+		options:    options,
+		version:    version,
+		providers:  newProviderRegistry(options, version),
+		locations:  newLocationRegistry(options),
+		references: newReferencePool(),
+		tagger:     newTagger(options),
+
+		resourceNames: newNameSequence(resourceNameParts, options.NameStyle),
+		outputNames:   newNameSequence(outputNameParts, options.NameStyle),
+		cloudNames:    newCloudNameSequence(options.NameStyle),
+		modules:       moduleAddresses(options.NameStyle, 8),
+	}, nil
+}
+
+// generateInstance generates a single instance of the resource at address,
+// with attributes that match the resource type and its location and refer
+// to resources generated before it. Managed resources whose type supports
+// resource identity usually also get an identity derived from those
+// attributes.
+func (g *generator) generateInstance(mode string, rt resourceType, address string, location awsLocation) (InstanceV4, error) {
+	var err error
+	instance := InstanceV4{SchemaVersion: rt.SchemaVersion}
+
+	ctx := newAttributeContext(g.locations, location, g.references, g.tagger, g.cloudNames)
+	attributes := rt.Attributes(ctx)
+	if size := sampleAttributeSize(g.options.AttributeSize, g.options.AttributeSizeMax); size > 0 {
+		if err := padAttributes(ctx, rt.Name, attributes, size); err != nil {
+			return instance, fmt.Errorf("failed to pad %s attributes: %w", rt.Name, err)
+		}
+	}
+	instance.Attributes, err = json.Marshal(attributes)
+	if err != nil {
+		return instance, fmt.Errorf("failed to marshal %s attributes: %w", rt.Name, err)
+	}
+	instance.Dependencies = slices.Sorted(slices.Values(ctx.dependencies))
+	g.references.add(rt.Name, reference{address: address, managed: mode == "managed", location: location, attributes: attributes})
+
+	if g.version.supportsSensitiveAttributes() {
+		instance.SensitiveAttributes, err = json.Marshal(sensitiveAttributePaths(attributes))
+		if err != nil {
+			return instance, fmt.Errorf("failed to marshal %s sensitive attributes: %w", rt.Name, err)
+		}
+	}
+
+	// Data sources never have an identity, and most of the time a managed
+	// resource that supports identity has one
+	if mode == "managed" && rt.Identity != nil && g.version.supportsIdentity() && rand.IntN(5) > 1 {
+		instance.Identity, err = json.Marshal(rt.Identity(location, attributes))
+		if err != nil {
+			return instance, fmt.Errorf("failed to marshal %s identity: %w", rt.Name, err)
+		}
+		identitySchemaVersion := rt.IdentitySchemaVersion
+		instance.IdentitySchemaVersion = &identitySchemaVersion
+	}
+
+	// Only managed resources carry provider private data
+	if mode == "managed" && rand.IntN(100) < g.options.PrivateChance {
+		instance.Private, err = generatePrivate(g.options.PrivateSize)
+		if err != nil {
+			return instance, fmt.Errorf("failed to generate %s private data: %w", rt.Name, err)
+		}
+	}
+
+	return instance, nil
+}
+
+// sensitiveAttributePaths returns the paths of the sensitive attributes
+// present in attributes, in the shape terraform writes them to state
+func sensitiveAttributePaths(attributes map[string]any) [][]map[string]string {
+	paths := [][]map[string]string{}
+	for _, name := range sensitiveAttributeNames {
+		if _, ok := attributes[name]; ok {
+			paths = append(paths, []map[string]string{
+				{"type": "get_attr", "value": name},
+			})
+		}
+	}
+	return paths
+}
@@ -0,0 +1,360 @@
+package statefaker
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+
+	"github.com/go-faker/faker/v4"
+)
+
+// googleRegions are the Google Cloud regions that generated google resources live in
+var googleRegions = []string{
+	"us-central1", "us-east1", "us-east4", "us-west1", "europe-west1", "europe-west2", "europe-west4", "asia-east1", "asia-southeast1",
+}
+
+// googleLocation is the project and region that every google resource in a
+// state belongs to
+type googleLocation struct {
+	Project       string
+	ProjectNumber string
+	Region        string
+}
+
+func generateGoogleLocation() googleLocation {
+	return googleLocation{
+		Project:       fmt.Sprintf("%s-%s-%06d", faker.Word(), workspaceEnvs[rand.IntN(len(workspaceEnvs))], rand.IntN(1000000)),
+		ProjectNumber: fmt.Sprintf("%012d", rand.Int64N(1e12)),
+		Region:        googleRegions[rand.IntN(len(googleRegions))],
+	}
+}
+
+// zone returns one of the zones of the region
+func (l googleLocation) zone() string {
+	return l.Region + "-" + []string{"a", "b", "c"}[rand.IntN(3)]
+}
+
+// selfLink returns the compute API URL of a resource, given its ID relative
+// to the API, such as projects/<project>/zones/<zone>/instances/<name>
+func (l googleLocation) selfLink(id string) string {
+	return "https://www.googleapis.com/compute/v1/" + id
+}
+
+// globalID returns the ID of a global compute resource in the project
+func (l googleLocation) globalID(collection, name string) string {
+	return fmt.Sprintf("projects/%s/global/%s/%s", l.Project, collection, name)
+}
+
+// regionalID returns the ID of a regional compute resource in the project
+func (l googleLocation) regionalID(collection, name string) string {
+	return fmt.Sprintf("projects/%s/regions/%s/%s/%s", l.Project, l.Region, collection, name)
+}
+
+// zonalID returns the ID of a zonal compute resource in the project
+func (l googleLocation) zonalID(zone, collection, name string) string {
+	return fmt.Sprintf("projects/%s/zones/%s/%s/%s", l.Project, zone, collection, name)
+}
+
+// serviceAccountEmail returns the email of a service account in the project
+func (l googleLocation) serviceAccountEmail(name string) string {
+	return fmt.Sprintf("%s@%s.iam.gserviceaccount.com", name, l.Project)
+}
+
+// generateServiceAccountID generates the ID of a service account, which is
+// between 6 and 30 characters and doesn't end with a hyphen
+func generateServiceAccountID() string {
+	id := fmt.Sprintf("%s-%s-sa", faker.Word(), []string{"app", "worker", "runner", "node"}[rand.IntN(4)])
+	return strings.TrimRight(id[:min(len(id), 30)], "-")
+}
+
+// googleFingerprint generates the fingerprint google uses to detect
+// concurrent changes to metadata, tags and labels
+func googleFingerprint() string {
+	b := make([]byte, 8)
+	for i := range b {
+		b[i] = byte(rand.IntN(256))
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// invalidLabelCharacters are the characters not allowed in google labels
+var invalidLabelCharacters = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// googleLabels returns the labels of a new google resource, derived from the
+// state's tags, along with its terraform_labels, which add the provider's
+// default labels, and effective_labels, which are all the labels the
+// resource has in Google Cloud
+func (c *attributeContext) googleLabels() (labels, terraformLabels, effectiveLabels map[string]string) {
+	tags, tagsAll := c.tags(nil)
+	label := func(s string) string {
+		s = invalidLabelCharacters.ReplaceAllString(strings.ToLower(s), "_")
+		return s[:min(len(s), 63)]
+	}
+	labels = make(map[string]string, len(tags))
+	for key, value := range tags {
+		labels[label(key)] = label(value)
+	}
+	terraformLabels = map[string]string{"goog-terraform-provisioned": "true"}
+	for key, value := range tagsAll {
+		terraformLabels[label(key)] = label(value)
+	}
+	return labels, terraformLabels, terraformLabels
+}
+
+// googleNetwork returns the ID of an existing network for a new google
+// resource, or of the project's default network when there is none
+func (c *attributeContext) googleNetwork() (network, subnetwork string) {
+	name := "default"
+	if networks := c.pick("google_compute_network", 1, 1); len(networks) > 0 {
+		name = networks[0]["name"].(string)
+	}
+	return c.google.globalID("networks", name), c.google.regionalID("subnetworks", name)
+}
+
+func generateGoogleComputeNetworkAttributes(ctx *attributeContext) map[string]any {
+	name := fmt.Sprintf("%s-vpc", ctx.name())
+	id := ctx.google.globalID("networks", name)
+	return map[string]any{
+		"id":                              id,
+		"name":                            name,
+		"project":                         ctx.google.Project,
+		"self_link":                       ctx.google.selfLink(id),
+		"description":                     "",
+		"auto_create_subnetworks":         false,
+		"routing_mode":                    []string{"REGIONAL", "GLOBAL"}[rand.IntN(2)],
+		"mtu":                             1460,
+		"gateway_ipv4":                    "",
+		"numeric_id":                      fmt.Sprint(rand.Int64N(9e18)),
+		"delete_default_routes_on_create": false,
+		"enable_ula_internal_ipv6":        false,
+		"network_firewall_policy_enforcement_order": "AFTER_CLASSIC_FIREWALL",
+		"timeouts": nil,
+	}
+}
+
+func generateGoogleComputeInstanceAttributes(ctx *attributeContext) map[string]any {
+	name := fmt.Sprintf("%s-vm", ctx.name())
+	zone := ctx.google.zone()
+	id := ctx.google.zonalID(zone, "instances", name)
+	network, subnetwork := ctx.googleNetwork()
+	labels, terraformLabels, effectiveLabels := ctx.googleLabels()
+	machineType := []string{"e2-medium", "e2-standard-4", "n2-standard-2", "n2-highmem-4", "c3-standard-8"}[rand.IntN(5)]
+	return map[string]any{
+		"id":                   id,
+		"name":                 name,
+		"project":              ctx.google.Project,
+		"zone":                 zone,
+		"self_link":            ctx.google.selfLink(id),
+		"instance_id":          fmt.Sprint(rand.Int64N(9e18)),
+		"machine_type":         machineType,
+		"cpu_platform":         []string{"Intel Broadwell", "Intel Cascade Lake", "AMD Milan"}[rand.IntN(3)],
+		"current_status":       "RUNNING",
+		"desired_status":       nil,
+		"can_ip_forward":       false,
+		"deletion_protection":  rand.IntN(2) == 0,
+		"hostname":             "",
+		"metadata":             map[string]string{"enable-oslogin": "TRUE"},
+		"metadata_fingerprint": googleFingerprint(),
+		"tags":                 pickStrings([]string{"http-server", "https-server", "allow-ssh", "internal"}, rand.IntN(3)),
+		"tags_fingerprint":     googleFingerprint(),
+		"network_interface": []map[string]any{
+			{
+				"name":               "nic0",
+				"network":            ctx.google.selfLink(network),
+				"subnetwork":         ctx.google.selfLink(subnetwork),
+				"subnetwork_project": ctx.google.Project,
+				"network_ip":         fmt.Sprintf("10.%d.%d.%d", rand.IntN(256), rand.IntN(256), rand.IntN(254)+1),
+				"stack_type":         "IPV4_ONLY",
+				"access_config":      []map[string]any{},
+				"alias_ip_range":     []map[string]any{},
+			},
+		},
+		"boot_disk": []map[string]any{
+			{
+				"auto_delete": true,
+				"device_name": "persistent-disk-0",
+				"mode":        "READ_WRITE",
+				"source":      ctx.google.selfLink(ctx.google.zonalID(zone, "disks", name)),
+				"initialize_params": []map[string]any{
+					{
+						"image": "https://www.googleapis.com/compute/v1/projects/debian-cloud/global/images/debian-12-bookworm-v20240910",
+						"size":  []int{10, 20, 50, 100}[rand.IntN(4)],
+						"type":  []string{"pd-standard", "pd-balanced", "pd-ssd"}[rand.IntN(3)],
+					},
+				},
+			},
+		},
+		"service_account": []map[string]any{
+			{
+				"email":  ctx.google.serviceAccountEmail(generateServiceAccountID()),
+				"scopes": []string{"https://www.googleapis.com/auth/cloud-platform"},
+			},
+		},
+		"scheduling": []map[string]any{
+			{
+				"automatic_restart":   true,
+				"on_host_maintenance": "MIGRATE",
+				"preemptible":         false,
+				"provisioning_model":  "STANDARD",
+			},
+		},
+		"labels":           labels,
+		"terraform_labels": terraformLabels,
+		"effective_labels": effectiveLabels,
+		"timeouts":         nil,
+	}
+}
+
+func generateGoogleStorageBucketAttributes(ctx *attributeContext) map[string]any {
+	name := fmt.Sprintf("%s-%s", ctx.google.Project, generateS3BucketName())
+	labels, terraformLabels, effectiveLabels := ctx.googleLabels()
+	location := []string{"US", "EU", strings.ToUpper(ctx.google.Region)}[rand.IntN(3)]
+	return map[string]any{
+		"id":                          name,
+		"name":                        name,
+		"project":                     ctx.google.Project,
+		"location":                    location,
+		"self_link":                   fmt.Sprintf("https://www.googleapis.com/storage/v1/b/%s", name),
+		"url":                         fmt.Sprintf("gs://%s", name),
+		"storage_class":               []string{"STANDARD", "NEARLINE", "COLDLINE"}[rand.IntN(3)],
+		"uniform_bucket_level_access": true,
+		"public_access_prevention":    "enforced",
+		"force_destroy":               false,
+		"requester_pays":              false,
+		"default_event_based_hold":    false,
+		"versioning":                  []map[string]any{{"enabled": rand.IntN(2) == 0}},
+		"lifecycle_rule":              []map[string]any{},
+		"soft_delete_policy":          []map[string]any{{"retention_duration_seconds": 604800, "effective_time": faker.Timestamp()}},
+		"labels":                      labels,
+		"terraform_labels":            terraformLabels,
+		"effective_labels":            effectiveLabels,
+		"timeouts":                    nil,
+	}
+}
+
+func generateGoogleProjectIAMMemberAttributes(ctx *attributeContext) map[string]any {
+	role := []string{
+		"roles/storage.objectViewer", "roles/storage.objectAdmin", "roles/cloudsql.client", "roles/container.developer",
+		"roles/logging.logWriter", "roles/monitoring.metricWriter", "roles/secretmanager.secretAccessor",
+	}[rand.IntN(7)]
+	// Roles are mostly granted to the service accounts of instances in the
+	// state, and otherwise to groups and users
+	member := fmt.Sprintf("group:%s@%s", []string{"platform", "data", "sre", "developers"}[rand.IntN(4)], faker.DomainName())
+	if instances := ctx.pick("google_compute_instance", 1, 1); len(instances) > 0 {
+		member = "serviceAccount:" + instances[0]["service_account"].([]map[string]any)[0]["email"].(string)
+	} else if rand.IntN(2) == 0 {
+		member = "user:" + faker.Email()
+	}
+	return map[string]any{
+		"id":        fmt.Sprintf("%s/%s/%s", ctx.google.Project, role, member),
+		"project":   ctx.google.Project,
+		"role":      role,
+		"member":    member,
+		"etag":      "BwY" + base64.StdEncoding.EncodeToString([]byte(faker.UUIDDigit()[:6])),
+		"condition": []map[string]any{},
+	}
+}
+
+func generateGoogleContainerClusterAttributes(ctx *attributeContext) map[string]any {
+	name := fmt.Sprintf("%s-gke", ctx.name())
+	// Clusters are either regional or zonal
+	location := ctx.google.Region
+	if rand.IntN(3) == 0 {
+		location = ctx.google.zone()
+	}
+	id := fmt.Sprintf("projects/%s/locations/%s/clusters/%s", ctx.google.Project, location, name)
+	network, subnetwork := ctx.googleNetwork()
+	labels, terraformLabels, effectiveLabels := ctx.googleLabels()
+	version := []string{"1.29.8-gke.1211000", "1.30.4-gke.1348000", "1.31.1-gke.1146000"}[rand.IntN(3)]
+	certificate := make([]byte, 1100)
+	for i := range certificate {
+		certificate[i] = byte(rand.IntN(256))
+	}
+	return map[string]any{
+		"id":                                id,
+		"name":                              name,
+		"project":                           ctx.google.Project,
+		"location":                          location,
+		"self_link":                         "https://container.googleapis.com/v1/" + id,
+		"network":                           network,
+		"subnetwork":                        subnetwork,
+		"endpoint":                          fmt.Sprintf("34.%d.%d.%d", rand.IntN(256), rand.IntN(256), rand.IntN(256)),
+		"master_version":                    version,
+		"min_master_version":                nil,
+		"node_version":                      version,
+		"initial_node_count":                1,
+		"remove_default_node_pool":          true,
+		"deletion_protection":               true,
+		"cluster_ipv4_cidr":                 fmt.Sprintf("10.%d.0.0/14", rand.IntN(64)*4),
+		"services_ipv4_cidr":                fmt.Sprintf("10.%d.0.0/20", rand.IntN(256)),
+		"label_fingerprint":                 faker.UUIDDigit()[:8],
+		"release_channel":                   []map[string]any{{"channel": []string{"RAPID", "REGULAR", "STABLE"}[rand.IntN(3)]}},
+		"workload_identity_config":          []map[string]any{{"workload_pool": ctx.google.Project + ".svc.id.goog"}},
+		"master_authorized_networks_config": []map[string]any{},
+		"master_auth": []map[string]any{
+			{
+				"client_certificate":        "",
+				"client_key":                "",
+				"cluster_ca_certificate":    base64.StdEncoding.EncodeToString([]byte("-----BEGIN CERTIFICATE-----\n" + base64.StdEncoding.EncodeToString(certificate) + "\n-----END CERTIFICATE-----\n")),
+				"client_certificate_config": []map[string]any{{"issue_client_certificate": false}},
+			},
+		},
+		"resource_labels":  labels,
+		"terraform_labels": terraformLabels,
+		"effective_labels": effectiveLabels,
+		"timeouts":         nil,
+	}
+}
+
+func generateGoogleSQLDatabaseInstanceAttributes(ctx *attributeContext) map[string]any {
+	name := fmt.Sprintf("%s-sql", ctx.name())
+	network, _ := ctx.googleNetwork()
+	privateIP := fmt.Sprintf("10.%d.%d.%d", rand.IntN(256), rand.IntN(256), rand.IntN(254)+1)
+	labels, _, _ := ctx.googleLabels()
+	return map[string]any{
+		"id":                            name,
+		"name":                          name,
+		"project":                       ctx.google.Project,
+		"region":                        ctx.google.Region,
+		"database_version":              []string{"POSTGRES_15", "POSTGRES_16", "MYSQL_8_0"}[rand.IntN(3)],
+		"connection_name":               fmt.Sprintf("%s:%s:%s", ctx.google.Project, ctx.google.Region, name),
+		"self_link":                     fmt.Sprintf("https://sqladmin.googleapis.com/sql/v1beta4/projects/%s/instances/%s", ctx.google.Project, name),
+		"first_ip_address":              privateIP,
+		"private_ip_address":            privateIP,
+		"public_ip_address":             "",
+		"ip_address":                    []map[string]any{{"ip_address": privateIP, "type": "PRIVATE", "time_to_retire": ""}},
+		"service_account_email_address": fmt.Sprintf("p%s-%s@gcp-sa-cloud-sql.iam.gserviceaccount.com", ctx.google.ProjectNumber, faker.UUIDDigit()[:6]),
+		"deletion_protection":           true,
+		"root_password":                 nil,
+		"settings": []map[string]any{
+			{
+				"tier":              []string{"db-f1-micro", "db-custom-2-7680", "db-custom-4-15360"}[rand.IntN(3)],
+				"availability_type": []string{"ZONAL", "REGIONAL"}[rand.IntN(2)],
+				"disk_size":         []int{10, 50, 100, 500}[rand.IntN(4)],
+				"disk_type":         "PD_SSD",
+				"disk_autoresize":   true,
+				"version":           rand.IntN(40) + 1,
+				"user_labels":       labels,
+				"ip_configuration": []map[string]any{
+					{
+						"ipv4_enabled":    false,
+						"private_network": network,
+						"ssl_mode":        "ENCRYPTED_ONLY",
+					},
+				},
+				"backup_configuration": []map[string]any{
+					{
+						"enabled":                        true,
+						"start_time":                     fmt.Sprintf("%02d:00", rand.IntN(24)),
+						"point_in_time_recovery_enabled": rand.IntN(2) == 0,
+						"transaction_log_retention_days": 7,
+					},
+				},
+				"location_preference": []map[string]any{{"zone": ctx.google.zone()}},
+			},
+		},
+		"timeouts": nil,
+	}
+}
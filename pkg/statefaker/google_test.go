@@ -0,0 +1,59 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGoogleResources(t *testing.T) {
+	state, err := NewFakeStateV4(WithResources(300))
+	if err != nil {
+		t.Fatalf("failed to generate fake state: %v", err)
+	}
+
+	projects := make(map[string]bool)
+	found := 0
+	for _, resource := range state.Resources {
+		if !strings.HasPrefix(resource.Type, "google_") {
+			continue
+		}
+		found++
+		if !strings.Contains(resource.Provider, `/google"]`) {
+			t.Errorf("%s uses provider %s", resourceAddress(resource), resource.Provider)
+		}
+
+		for _, instance := range resource.Instances {
+			var attributes struct {
+				ID       string `json:"id"`
+				Project  string `json:"project"`
+				SelfLink string `json:"self_link"`
+				Zone     string `json:"zone"`
+			}
+			if err := json.Unmarshal(instance.Attributes, &attributes); err != nil {
+				t.Fatalf("failed to decode attributes: %v", err)
+			}
+			projects[attributes.Project] = true
+
+			switch resource.Type {
+			case "google_compute_network", "google_compute_instance", "google_container_cluster":
+				if !strings.HasPrefix(attributes.ID, "projects/"+attributes.Project+"/") {
+					t.Errorf("%s ID %s is not in project %s", resourceAddress(resource), attributes.ID, attributes.Project)
+				}
+				if !strings.HasSuffix(attributes.SelfLink, "/"+attributes.ID) {
+					t.Errorf("%s self link %s does not refer to %s", resourceAddress(resource), attributes.SelfLink, attributes.ID)
+				}
+			}
+			if resource.Type == "google_compute_instance" && !strings.Contains(attributes.ID, "/zones/"+attributes.Zone+"/") {
+				t.Errorf("%s ID %s is not in zone %s", resourceAddress(resource), attributes.ID, attributes.Zone)
+			}
+		}
+	}
+
+	if found == 0 {
+		t.Fatal("expected google resources")
+	}
+	if len(projects) != 1 {
+		t.Errorf("expected every google resource to share one project, got %v", projects)
+	}
+}
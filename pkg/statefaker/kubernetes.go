@@ -0,0 +1,538 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-faker/faker/v4"
+)
+
+// kubernetesNamespaces are the namespaces kubernetes resources and helm
+// releases are installed into
+var kubernetesNamespaces = []string{
+	"default", "kube-system", "monitoring", "ingress-nginx", "cert-manager", "argocd", "payments", "platform", "data",
+}
+
+// helmChart is a chart that helm_release resources install
+type helmChart struct {
+	Name       string
+	Repository string
+	Versions   []string
+	AppVersion string
+	Namespace  string
+}
+
+var helmCharts = []helmChart{
+	{Name: "ingress-nginx", Repository: "https://kubernetes.github.io/ingress-nginx", Versions: []string{"4.10.1", "4.11.2", "4.12.0"}, AppVersion: "1.11.2", Namespace: "ingress-nginx"},
+	{Name: "cert-manager", Repository: "https://charts.jetstack.io", Versions: []string{"v1.14.5", "v1.15.3", "v1.16.1"}, AppVersion: "v1.15.3", Namespace: "cert-manager"},
+	{Name: "kube-prometheus-stack", Repository: "https://prometheus-community.github.io/helm-charts", Versions: []string{"58.2.1", "61.3.0", "65.1.1"}, AppVersion: "v0.76.1", Namespace: "monitoring"},
+	{Name: "argo-cd", Repository: "https://argoproj.github.io/argo-helm", Versions: []string{"6.7.3", "7.4.1", "7.6.8"}, AppVersion: "v2.12.4", Namespace: "argocd"},
+	{Name: "external-dns", Repository: "https://kubernetes-sigs.github.io/external-dns", Versions: []string{"1.14.4", "1.15.0"}, AppVersion: "0.15.0", Namespace: "kube-system"},
+	{Name: "aws-load-balancer-controller", Repository: "https://aws.github.io/eks-charts", Versions: []string{"1.7.2", "1.8.1", "1.9.0"}, AppVersion: "v2.9.0", Namespace: "kube-system"},
+	{Name: "cluster-autoscaler", Repository: "https://kubernetes.github.io/autoscaler", Versions: []string{"9.37.0", "9.43.0"}, AppVersion: "1.31.0", Namespace: "kube-system"},
+	{Name: "datadog", Repository: "https://helm.datadoghq.com", Versions: []string{"3.69.3", "3.74.1"}, AppVersion: "7", Namespace: "monitoring"},
+	{Name: "redis", Repository: "oci://registry-1.docker.io/bitnamicharts", Versions: []string{"19.6.4", "20.1.5"}, AppVersion: "7.4.0", Namespace: "data"},
+	{Name: "postgresql", Repository: "oci://registry-1.docker.io/bitnamicharts", Versions: []string{"15.5.20", "16.0.1"}, AppVersion: "16.4.0", Namespace: "data"},
+}
+
+// generateKubernetesMetadata generates the metadata block shared by every
+// kubernetes resource
+func generateKubernetesMetadata(name, namespace string, labels map[string]string) []map[string]any {
+	annotations := map[string]string{}
+	if rand.IntN(2) == 0 {
+		annotations["meta.helm.sh/release-name"] = name
+		annotations["meta.helm.sh/release-namespace"] = namespace
+	}
+	return []map[string]any{
+		{
+			"name":             name,
+			"namespace":        namespace,
+			"generate_name":    "",
+			"labels":           labels,
+			"annotations":      annotations,
+			"generation":       rand.IntN(20) + 1,
+			"resource_version": strconv.Itoa(rand.IntN(90000000) + 10000000),
+			"uid":              faker.UUIDHyphenated(),
+		},
+	}
+}
+
+func generateKubernetesConfigMapAttributes(ctx *attributeContext) map[string]any {
+	name := fmt.Sprintf("%s-config", ctx.name())
+	namespace := kubernetesNamespaces[rand.IntN(len(kubernetesNamespaces))]
+
+	data := map[string]string{
+		"LOG_LEVEL": []string{"debug", "info", "warn", "error"}[rand.IntN(4)],
+		"REGION":    ctx.location.Region,
+	}
+	// Config maps often carry whole configuration files
+	for range rand.IntN(4) {
+		values := generateHelmValues(rand.IntN(8*1024) + 512)
+		data[fmt.Sprintf("%s.yaml", faker.Word())] = renderYAML(values, 0)
+	}
+
+	return map[string]any{
+		"id":          fmt.Sprintf("%s/%s", namespace, name),
+		"metadata":    generateKubernetesMetadata(name, namespace, map[string]string{"app.kubernetes.io/name": name}),
+		"data":        data,
+		"binary_data": map[string]string{},
+		"immutable":   false,
+	}
+}
+
+func generateKubernetesDeploymentAttributes(ctx *attributeContext) map[string]any {
+	name := ctx.name()
+	namespace := kubernetesNamespaces[rand.IntN(len(kubernetesNamespaces))]
+	labels := map[string]string{
+		"app.kubernetes.io/name":       name,
+		"app.kubernetes.io/managed-by": "terraform",
+	}
+
+	// Deployments load their configuration from an existing config map, in
+	// the config map's namespace
+	envFrom := []map[string]any{}
+	if configMaps := ctx.pick("kubernetes_config_map", 1, 1); len(configMaps) > 0 {
+		metadata := configMaps[0]["metadata"].([]map[string]any)[0]
+		namespace = metadata["namespace"].(string)
+		envFrom = append(envFrom, map[string]any{
+			"prefix":         "",
+			"config_map_ref": []map[string]any{{"name": metadata["name"], "optional": false}},
+			"secret_ref":     []map[string]any{},
+		})
+	}
+
+	containers := make([]map[string]any, rand.IntN(3)+1)
+	for i := range containers {
+		containerName := name
+		if i > 0 {
+			containerName = []string{"istio-proxy", "fluent-bit", "cloud-sql-proxy", "vault-agent"}[rand.IntN(4)]
+		}
+		port := []int{80, 3000, 8080, 9090}[rand.IntN(4)]
+		env := []map[string]any{}
+		for range rand.IntN(8) {
+			env = append(env, map[string]any{
+				"name":       strings.ToUpper(faker.Word()) + "_" + strings.ToUpper(faker.Word()),
+				"value":      faker.Word(),
+				"value_from": []map[string]any{},
+			})
+		}
+		containers[i] = map[string]any{
+			"name":              containerName,
+			"image":             fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com/%s:%s", ctx.location.AccountID, ctx.location.Region, containerName, faker.UUIDDigit()[:7]),
+			"image_pull_policy": "IfNotPresent",
+			"args":              []string{},
+			"command":           []string{},
+			"port": []map[string]any{
+				{"container_port": port, "name": "http", "protocol": "TCP", "host_ip": "", "host_port": 0},
+			},
+			"env":      env,
+			"env_from": envFrom,
+			"resources": []map[string]any{
+				{
+					"limits":   map[string]string{"cpu": []string{"500m", "1", "2"}[rand.IntN(3)], "memory": []string{"512Mi", "1Gi", "2Gi"}[rand.IntN(3)]},
+					"requests": map[string]string{"cpu": []string{"100m", "250m", "500m"}[rand.IntN(3)], "memory": []string{"128Mi", "256Mi", "512Mi"}[rand.IntN(3)]},
+				},
+			},
+			"liveness_probe": []map[string]any{
+				{
+					"http_get":              []map[string]any{{"path": "/healthz", "port": strconv.Itoa(port), "scheme": "HTTP", "host": ""}},
+					"initial_delay_seconds": 10,
+					"period_seconds":        10,
+					"timeout_seconds":       1,
+					"failure_threshold":     3,
+					"success_threshold":     1,
+				},
+			},
+			"termination_message_path": "/dev/termination-log",
+		}
+	}
+
+	return map[string]any{
+		"id":       fmt.Sprintf("%s/%s", namespace, name),
+		"metadata": generateKubernetesMetadata(name, namespace, labels),
+		"spec": []map[string]any{
+			{
+				// The provider stores replicas as a string
+				"replicas":                  strconv.Itoa(rand.IntN(6) + 1),
+				"min_ready_seconds":         0,
+				"paused":                    false,
+				"progress_deadline_seconds": 600,
+				"revision_history_limit":    10,
+				"selector":                  []map[string]any{{"match_labels": map[string]string{"app.kubernetes.io/name": name}, "match_expressions": []any{}}},
+				"strategy": []map[string]any{
+					{
+						"type":           "RollingUpdate",
+						"rolling_update": []map[string]any{{"max_surge": "25%", "max_unavailable": "25%"}},
+					},
+				},
+				"template": []map[string]any{
+					{
+						"metadata": []map[string]any{
+							{
+								"labels":      labels,
+								"annotations": map[string]string{"prometheus.io/scrape": "true"},
+							},
+						},
+						"spec": []map[string]any{
+							{
+								"container":                        containers,
+								"service_account_name":             name,
+								"restart_policy":                   "Always",
+								"dns_policy":                       "ClusterFirst",
+								"node_selector":                    map[string]string{"kubernetes.io/os": "linux"},
+								"termination_grace_period_seconds": 30,
+							},
+						},
+					},
+				},
+			},
+		},
+		"wait_for_rollout": true,
+		"timeouts":         nil,
+	}
+}
+
+func generateKubernetesManifestAttributes(ctx *attributeContext) map[string]any {
+	name := ctx.name()
+	namespace := kubernetesNamespaces[rand.IntN(len(kubernetesNamespaces))]
+
+	var manifest map[string]any
+	switch rand.IntN(3) {
+	case 0:
+		manifest = map[string]any{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+			"spec": map[string]any{
+				"secretName": name + "-tls",
+				"dnsNames":   []any{faker.DomainName(), faker.DomainName()},
+				"issuerRef":  map[string]any{"kind": "ClusterIssuer", "name": "letsencrypt-prod"},
+			},
+		}
+	case 1:
+		manifest = map[string]any{
+			"apiVersion": "monitoring.coreos.com/v1",
+			"kind":       "ServiceMonitor",
+			"spec": map[string]any{
+				"selector":  map[string]any{"matchLabels": map[string]any{"app.kubernetes.io/name": name}},
+				"endpoints": []any{map[string]any{"port": "http", "path": "/metrics", "interval": "30s"}},
+			},
+		}
+	default:
+		manifest = map[string]any{
+			"apiVersion": "argoproj.io/v1alpha1",
+			"kind":       "Application",
+			"spec": map[string]any{
+				"project": "default",
+				"source": map[string]any{
+					"repoURL":        fmt.Sprintf("https://github.com/%s/%s.git", faker.Word(), faker.Word()),
+					"path":           fmt.Sprintf("deploy/%s", name),
+					"targetRevision": "HEAD",
+				},
+				"destination": map[string]any{"server": "https://kubernetes.default.svc", "namespace": namespace},
+				"syncPolicy":  map[string]any{"automated": map[string]any{"prune": true, "selfHeal": true}},
+			},
+		}
+	}
+	manifest["metadata"] = map[string]any{"name": name, "namespace": namespace}
+
+	// The object is the manifest as the API server returns it, with defaults
+	// and server managed fields filled in
+	object := maps.Clone(manifest)
+	object["metadata"] = map[string]any{
+		"name":              name,
+		"namespace":         namespace,
+		"uid":               faker.UUIDHyphenated(),
+		"resourceVersion":   strconv.Itoa(rand.IntN(90000000) + 10000000),
+		"generation":        rand.IntN(20) + 1,
+		"creationTimestamp": time.Unix(int64(legacyEpoch+rand.IntN(200000000)), 0).UTC().Format(time.RFC3339),
+		"labels":            nil,
+		"annotations":       nil,
+	}
+
+	// Both attributes are dynamically typed, so state records their type
+	// alongside their value
+	return map[string]any{
+		"manifest":        map[string]any{"value": manifest, "type": ctyTypeOf(manifest)},
+		"object":          map[string]any{"value": object, "type": ctyTypeOf(object)},
+		"computed_fields": nil,
+		"field_manager":   []map[string]any{},
+		"wait":            []map[string]any{},
+		"wait_for":        nil,
+		"timeouts":        []map[string]any{},
+	}
+}
+
+// ctyTypeOf returns the JSON encoding of the type terraform infers for a
+// dynamically typed value
+func ctyTypeOf(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		attributes := make(map[string]any, len(v))
+		for key, item := range v {
+			attributes[key] = ctyTypeOf(item)
+		}
+		return []any{"object", attributes}
+	case []any:
+		elements := make([]any, len(v))
+		for i, item := range v {
+			elements[i] = ctyTypeOf(item)
+		}
+		return []any{"tuple", elements}
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case int, float64:
+		return "number"
+	default:
+		return "dynamic"
+	}
+}
+
+// helmValuesSizes are the rough sizes in bytes of typical, large and huge
+// chart values. Huge values embed dashboards and alerting rules.
+var helmValuesSizes = [3][2]int{{512, 4 * 1024}, {4 * 1024, 64 * 1024}, {64 * 1024, 1024 * 1024}}
+
+func generateHelmReleaseAttributes(ctx *attributeContext) map[string]any {
+	chart := helmCharts[rand.IntN(len(helmCharts))]
+	name := chart.Name
+	if rand.IntN(2) == 0 {
+		name = fmt.Sprintf("%s-%s", chart.Name, faker.Word())
+	}
+	version := chart.Versions[rand.IntN(len(chart.Versions))]
+	revision := rand.IntN(40) + 1
+
+	sizes := helmValuesSizes[0]
+	switch pick := rand.IntN(10); {
+	case pick == 0:
+		sizes = helmValuesSizes[2]
+	case pick < 4:
+		sizes = helmValuesSizes[1]
+	}
+	values := generateHelmValues(rand.IntN(sizes[1]-sizes[0]) + sizes[0])
+	valuesJSON, _ := json.Marshal(values)
+
+	firstDeployed := legacyEpoch + rand.IntN(150000000)
+	lastDeployed := firstDeployed + rand.IntN(50000000)
+
+	attributes := map[string]any{
+		"id":                         name,
+		"name":                       name,
+		"namespace":                  chart.Namespace,
+		"chart":                      chart.Name,
+		"repository":                 chart.Repository,
+		"version":                    version,
+		"values":                     []string{renderYAML(values, 0)},
+		"set":                        []map[string]any{{"name": "replicaCount", "value": strconv.Itoa(rand.IntN(3) + 1), "type": ""}},
+		"set_sensitive":              []map[string]any{},
+		"status":                     "deployed",
+		"atomic":                     rand.IntN(2) == 0,
+		"cleanup_on_fail":            false,
+		"create_namespace":           rand.IntN(2) == 0,
+		"dependency_update":          false,
+		"description":                nil,
+		"devel":                      nil,
+		"disable_crd_hooks":          false,
+		"disable_openapi_validation": false,
+		"disable_webhooks":           false,
+		"force_update":               false,
+		"lint":                       false,
+		"max_history":                []int{0, 5, 10}[rand.IntN(3)],
+		"recreate_pods":              false,
+		"render_subchart_notes":      true,
+		"replace":                    false,
+		"reset_values":               false,
+		"reuse_values":               false,
+		"skip_crds":                  false,
+		"timeout":                    []int{300, 600, 900}[rand.IntN(3)],
+		"verify":                     false,
+		"wait":                       true,
+		"wait_for_jobs":              false,
+		"metadata": []map[string]any{
+			{
+				"app_version":    chart.AppVersion,
+				"chart":          chart.Name,
+				"first_deployed": firstDeployed,
+				"last_deployed":  lastDeployed,
+				"name":           name,
+				"namespace":      chart.Namespace,
+				"notes":          generateHelmNotes(chart, name),
+				"revision":       revision,
+				"values":         string(valuesJSON),
+				"version":        version,
+			},
+		},
+		"manifest": nil,
+	}
+
+	// With the manifest experiment enabled, the rendered manifests are stored
+	// as well, and are larger again than the values
+	if rand.IntN(3) == 0 {
+		attributes["manifest"] = generateHelmManifest(name, chart.Namespace, values)
+	}
+	return attributes
+}
+
+// generateHelmValues generates chart values of roughly size bytes once
+// rendered as YAML
+func generateHelmValues(size int) map[string]any {
+	values := map[string]any{
+		"replicaCount": rand.IntN(3) + 1,
+		"image": map[string]any{
+			"repository": fmt.Sprintf("%s/%s", faker.Word(), faker.Word()),
+			"tag":        fmt.Sprintf("v%d.%d.%d", rand.IntN(3), rand.IntN(20), rand.IntN(10)),
+			"pullPolicy": "IfNotPresent",
+		},
+		"resources": map[string]any{
+			"limits":   map[string]any{"cpu": "500m", "memory": "512Mi"},
+			"requests": map[string]any{"cpu": "100m", "memory": "128Mi"},
+		},
+		"ingress": map[string]any{
+			"enabled":   rand.IntN(2) == 0,
+			"className": "nginx",
+			"hosts":     []any{map[string]any{"host": faker.DomainName(), "paths": []any{map[string]any{"path": "/", "pathType": "Prefix"}}}},
+		},
+		"nodeSelector": map[string]any{"kubernetes.io/os": "linux"},
+		"tolerations":  []any{},
+	}
+
+	// Large values are mostly alerting rules
+	var rules []any
+	for written := len(renderYAML(values, 0)); written < size; {
+		word := faker.Word()
+		rule := map[string]any{
+			"alert":  strings.ToUpper(word[:1]) + word[1:] + "High",
+			"expr":   fmt.Sprintf("sum(rate(%s_%s_total{job=%q}[5m])) by (instance) > %d", faker.Word(), faker.Word(), faker.Word(), rand.IntN(1000)),
+			"for":    []string{"1m", "5m", "15m"}[rand.IntN(3)],
+			"labels": map[string]any{"severity": []string{"warning", "critical"}[rand.IntN(2)]},
+			"annotations": map[string]any{
+				"summary":     faker.Sentence(),
+				"description": faker.Paragraph(),
+			},
+		}
+		rules = append(rules, rule)
+		written += len(renderYAML(rule, 3))
+	}
+	if len(rules) > 0 {
+		values["additionalPrometheusRules"] = []any{
+			map[string]any{"name": "alerts", "groups": []any{map[string]any{"name": "default", "rules": rules}}},
+		}
+	}
+	return values
+}
+
+// renderYAML renders a value as YAML, indented by the given number of
+// levels, with map keys in order
+func renderYAML(value any, indent int) string {
+	var b strings.Builder
+	writeYAML(&b, value, indent)
+	return b.String()
+}
+
+func writeYAML(b *strings.Builder, value any, indent int) {
+	prefix := strings.Repeat("  ", indent)
+	switch v := value.(type) {
+	case map[string]any:
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			switch item := v[key].(type) {
+			case map[string]any:
+				if len(item) == 0 {
+					fmt.Fprintf(b, "%s%s: {}\n", prefix, key)
+					continue
+				}
+				fmt.Fprintf(b, "%s%s:\n", prefix, key)
+				writeYAML(b, item, indent+1)
+			case []any:
+				if len(item) == 0 {
+					fmt.Fprintf(b, "%s%s: []\n", prefix, key)
+					continue
+				}
+				fmt.Fprintf(b, "%s%s:\n", prefix, key)
+				writeYAML(b, item, indent)
+			default:
+				fmt.Fprintf(b, "%s%s: %s\n", prefix, key, yamlScalar(item))
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if m, ok := item.(map[string]any); ok && len(m) > 0 {
+				// The first key of a map shares the line of its list item
+				rendered := renderYAML(m, indent+1)
+				fmt.Fprintf(b, "%s- %s", prefix, strings.TrimPrefix(rendered, prefix+"  "))
+				continue
+			}
+			fmt.Fprintf(b, "%s- %s\n", prefix, yamlScalar(item))
+		}
+	default:
+		fmt.Fprintf(b, "%s%s\n", prefix, yamlScalar(v))
+	}
+}
+
+func yamlScalar(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case nil:
+		return "null"
+	}
+	return fmt.Sprint(value)
+}
+
+// generateHelmNotes generates the NOTES.txt a chart prints after install
+func generateHelmNotes(chart helmChart, name string) string {
+	return fmt.Sprintf("%s has been installed.\n\nTo check the status of the release, run:\n\n  kubectl --namespace %s get pods -l \"app.kubernetes.io/instance=%s\"\n\n%s\n",
+		chart.Name, chart.Namespace, name, faker.Paragraph())
+}
+
+// generateHelmManifest generates the rendered manifests of a release, as the
+// JSON encoded map of resource keys to objects that the helm provider stores
+func generateHelmManifest(name, namespace string, values map[string]any) string {
+	labels := map[string]any{
+		"app.kubernetes.io/instance":   name,
+		"app.kubernetes.io/managed-by": "Helm",
+	}
+	metadata := map[string]any{"name": name, "namespace": namespace, "labels": labels}
+	manifests := map[string]any{
+		fmt.Sprintf("apps/v1, Kind=Deployment, Namespace=%s, Name=%s", namespace, name): map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   metadata,
+			"spec": map[string]any{
+				"replicas": values["replicaCount"],
+				"selector": map[string]any{"matchLabels": labels},
+				"template": map[string]any{
+					"metadata": map[string]any{"labels": labels},
+					"spec": map[string]any{
+						"containers": []any{map[string]any{"name": name, "image": values["image"], "resources": values["resources"]}},
+					},
+				},
+			},
+		},
+		fmt.Sprintf("v1, Kind=Service, Namespace=%s, Name=%s", namespace, name): map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata":   metadata,
+			"spec": map[string]any{
+				"ports":    []any{map[string]any{"name": "http", "port": 80, "targetPort": "http"}},
+				"selector": labels,
+			},
+		},
+	}
+	if rules, ok := values["additionalPrometheusRules"]; ok {
+		manifests[fmt.Sprintf("monitoring.coreos.com/v1, Kind=PrometheusRule, Namespace=%s, Name=%s", namespace, name)] = map[string]any{
+			"apiVersion": "monitoring.coreos.com/v1",
+			"kind":       "PrometheusRule",
+			"metadata":   metadata,
+			"spec":       rules.([]any)[0],
+		}
+	}
+
+	manifest, _ := json.Marshal(manifests)
+	return string(manifest)
+}
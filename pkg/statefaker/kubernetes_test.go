@@ -0,0 +1,98 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKubernetesResources(t *testing.T) {
+	state, err := NewFakeStateV4(WithResources(300))
+	if err != nil {
+		t.Fatalf("failed to generate fake state: %v", err)
+	}
+
+	found := make(map[string]bool)
+	for _, resource := range state.Resources {
+		provider := ""
+		switch {
+		case strings.HasPrefix(resource.Type, "kubernetes_"):
+			provider = "/kubernetes\"]"
+		case strings.HasPrefix(resource.Type, "helm_"):
+			provider = "/helm\"]"
+		default:
+			continue
+		}
+		found[resource.Type] = true
+		if !strings.Contains(resource.Provider, provider) {
+			t.Errorf("%s uses provider %s", resourceAddress(resource), resource.Provider)
+		}
+
+		for _, instance := range resource.Instances {
+			switch resource.Type {
+			case "helm_release":
+				var attributes struct {
+					Values   []string `json:"values"`
+					Metadata []struct {
+						Values string `json:"values"`
+					} `json:"metadata"`
+				}
+				if err := json.Unmarshal(instance.Attributes, &attributes); err != nil {
+					t.Fatalf("failed to decode attributes: %v", err)
+				}
+				if len(attributes.Values) != 1 || !strings.Contains(attributes.Values[0], "replicaCount: ") {
+					t.Errorf("%s has unexpected values %v", resourceAddress(resource), attributes.Values)
+				}
+				var values map[string]any
+				if len(attributes.Metadata) != 1 || json.Unmarshal([]byte(attributes.Metadata[0].Values), &values) != nil {
+					t.Errorf("%s metadata values are not a JSON document", resourceAddress(resource))
+				}
+			case "kubernetes_manifest":
+				var attributes struct {
+					Manifest struct {
+						Value map[string]any `json:"value"`
+						Type  any            `json:"type"`
+					} `json:"manifest"`
+				}
+				if err := json.Unmarshal(instance.Attributes, &attributes); err != nil {
+					t.Fatalf("failed to decode attributes: %v", err)
+				}
+				// Decoded numbers are float64 rather than int, which both
+				// have the number type
+				expected, _ := json.Marshal(ctyTypeOf(attributes.Manifest.Value))
+				actual, _ := json.Marshal(attributes.Manifest.Type)
+				if !reflect.DeepEqual(expected, actual) {
+					t.Errorf("%s manifest has type %s, expected %s", resourceAddress(resource), actual, expected)
+				}
+			}
+		}
+	}
+
+	for _, resourceType := range []string{"kubernetes_config_map", "kubernetes_deployment", "kubernetes_manifest", "helm_release"} {
+		if !found[resourceType] {
+			t.Errorf("expected %s resources", resourceType)
+		}
+	}
+}
+
+func TestRenderYAML(t *testing.T) {
+	rendered := renderYAML(map[string]any{
+		"image":       map[string]any{"tag": "v1"},
+		"replicas":    2,
+		"tolerations": []any{},
+		"hosts":       []any{map[string]any{"host": "example.com", "paths": []any{"/"}}},
+	}, 0)
+	expected := `hosts:
+- host: "example.com"
+  paths:
+  - "/"
+image:
+  tag: "v1"
+replicas: 2
+tolerations: []
+`
+	if rendered != expected {
+		t.Errorf("unexpected YAML:\n%s\nexpected:\n%s", rendered, expected)
+	}
+}
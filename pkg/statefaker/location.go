@@ -0,0 +1,118 @@
+package statefaker
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// awsRegions are the regions that generated resources live in
+var awsRegions = []string{
+	"us-east-1", "us-east-2", "us-west-2", "eu-west-1", "eu-central-1",
+	"ap-southeast-1", "ap-southeast-2", "ap-northeast-1", "ca-central-1", "sa-east-1",
+}
+
+// awsLocation is the account and region that a provider configuration
+// manages resources in
+type awsLocation struct {
+	AccountID string
+	Region    string
+}
+
+func generateAWSLocation() awsLocation {
+	return awsLocation{AccountID: generateAWSAccountID(), Region: generateAWSRegion()}
+}
+
+// arn returns the ARN of a regional resource in this location
+func (l awsLocation) arn(service, resource string) string {
+	return fmt.Sprintf("arn:aws:%s:%s:%s:%s", service, l.Region, l.AccountID, resource)
+}
+
+// globalARN returns the ARN of a resource in this account that belongs to no
+// region, such as an IAM role
+func (l awsLocation) globalARN(service, resource string) string {
+	return fmt.Sprintf("arn:aws:%s::%s:%s", service, l.AccountID, resource)
+}
+
+// availabilityZone returns one of the availability zones of the region
+func (l awsLocation) availabilityZone() string {
+	return l.Region + []string{"a", "b", "c"}[rand.IntN(3)]
+}
+
+// s3ARN returns the ARN of an S3 bucket or object. S3 ARNs name neither the
+// region nor the account.
+func s3ARN(resource string) string {
+	return "arn:aws:s3:::" + resource
+}
+
+// locationRegistry chooses, once per state, the accounts and regions the
+// state's resources live in, and assigns one of each to every provider
+// configuration so that all resources using a configuration agree. Every
+// azurerm resource shares a single subscription, and every google resource a
+// single project.
+type locationRegistry struct {
+	accounts       []string
+	regions        []string
+	configurations map[string]awsLocation
+	azure          azureLocation
+	google         googleLocation
+}
+
+func newLocationRegistry(options Options) *locationRegistry {
+	r := &locationRegistry{
+		configurations: make(map[string]awsLocation),
+		azure:          generateAzureLocation(),
+		google:         generateGoogleLocation(),
+	}
+	for range max(options.NumAccounts, 1) {
+		r.accounts = append(r.accounts, generateAWSAccountID())
+	}
+	for _, i := range rand.Perm(len(awsRegions))[:min(max(options.NumRegions, 1), len(awsRegions))] {
+		r.regions = append(r.regions, awsRegions[i])
+	}
+	return r
+}
+
+// primary returns the location of the default provider configuration, where
+// most resources live
+func (r *locationRegistry) primary() awsLocation {
+	return awsLocation{AccountID: r.accounts[0], Region: r.regions[0]}
+}
+
+// location returns the location of the given provider configuration. The
+// default configuration always uses the primary location, while aliased
+// configurations are assigned any of the state's accounts and regions.
+func (r *locationRegistry) location(configuration string, aliased bool) awsLocation {
+	if !aliased {
+		return r.primary()
+	}
+	if location, ok := r.configurations[configuration]; ok {
+		return location
+	}
+	location := awsLocation{
+		AccountID: r.accounts[rand.IntN(len(r.accounts))],
+		Region:    r.regions[rand.IntN(len(r.regions))],
+	}
+	r.configurations[configuration] = location
+	return location
+}
+
+// stateLocation guesses the primary location of an existing state from the
+// first resource with both a region and an ARN naming an account
+func stateLocation(state *StateV4) awsLocation {
+	for _, resource := range state.Resources {
+		for _, instance := range resource.Instances {
+			attributes, err := decodeValue(instance.Attributes)
+			object, ok := attributes.(map[string]any)
+			if err != nil || !ok {
+				continue
+			}
+			region, _ := object["region"].(string)
+			accountID := arnAccountID(object)
+			if region != "" && accountID != "" && !strings.ContainsAny(accountID, "/:") {
+				return awsLocation{AccountID: accountID, Region: region}
+			}
+		}
+	}
+	return generateAWSLocation()
+}
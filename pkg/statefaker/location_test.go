@@ -0,0 +1,93 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// regionalARN matches ARNs that name both a region and an account
+var regionalARN = regexp.MustCompile(`^arn:aws:[\w-]+:([\w-]+):(\d{12}):`)
+
+func TestLocationsConsistent(t *testing.T) {
+	t.Run("single", func(t *testing.T) {
+		state, err := NewFakeStateV4(WithResources(100))
+		if err != nil {
+			t.Fatalf("failed to generate fake state: %v", err)
+		}
+
+		accounts := make(map[string]bool)
+		regions := make(map[string]bool)
+		for _, resource := range state.Resources {
+			// Other providers have locations of their own
+			if !strings.HasPrefix(resource.Type, "aws_") {
+				continue
+			}
+			for _, instance := range resource.Instances {
+				var attributes map[string]any
+				if err := json.Unmarshal(instance.Attributes, &attributes); err != nil {
+					t.Fatalf("failed to decode attributes: %v", err)
+				}
+				arn, _ := attributes["arn"].(string)
+				region, _ := attributes["region"].(string)
+				if region != "" {
+					regions[region] = true
+				}
+				if match := regionalARN.FindStringSubmatch(arn); match != nil {
+					accounts[match[2]] = true
+					if match[1] != region {
+						t.Errorf("%s has ARN %s in a different region to %s", resource.Type, arn, region)
+					}
+				}
+
+				switch resource.Type {
+				case "aws_s3_bucket":
+					if arn != "arn:aws:s3:::"+attributes["bucket"].(string) {
+						t.Errorf("unexpected S3 bucket ARN %s", arn)
+					}
+					if !strings.Contains(attributes["bucket_regional_domain_name"].(string), ".s3."+region+".") {
+						t.Errorf("bucket domain name %s is not in %s", attributes["bucket_regional_domain_name"], region)
+					}
+				case "aws_iam_role", "aws_iam_user":
+					if !strings.HasPrefix(arn, "arn:aws:iam::") {
+						t.Errorf("expected an IAM ARN without a region, got %s", arn)
+					}
+				}
+			}
+		}
+
+		if len(accounts) != 1 || len(regions) != 1 {
+			t.Errorf("expected one account and region, got %v and %v", accounts, regions)
+		}
+	})
+
+	t.Run("multiple", func(t *testing.T) {
+		state, err := NewFakeStateV4(WithResources(100), WithAccounts(3), WithRegions(3), WithProviderAliasChance(50))
+		if err != nil {
+			t.Fatalf("failed to generate fake state: %v", err)
+		}
+
+		// Every resource using a provider configuration shares its location
+		locations := make(map[string]string)
+		for _, resource := range state.Resources {
+			configuration := strings.TrimPrefix(resource.Provider, resource.Module+".")
+			for _, instance := range resource.Instances {
+				var attributes map[string]any
+				if err := json.Unmarshal(instance.Attributes, &attributes); err != nil {
+					t.Fatalf("failed to decode attributes: %v", err)
+				}
+				arn, _ := attributes["arn"].(string)
+				match := regionalARN.FindStringSubmatch(arn)
+				if match == nil {
+					continue
+				}
+				location := match[1] + "/" + match[2]
+				if existing, ok := locations[configuration]; ok && existing != location {
+					t.Errorf("%s has resources in both %s and %s", configuration, existing, location)
+				}
+				locations[configuration] = location
+			}
+		}
+	})
+}
@@ -0,0 +1,548 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-faker/faker/v4"
+)
+
+// MutationOptions configures the changes Mutate makes to a state. Each
+// chance is the percentage chance (0-100) that an eligible resource or
+// output is changed in that way.
+type MutationOptions struct {
+	DriftChance  int // percentage chance that an instance's tags or size drift
+	ScaleChance  int // percentage chance that a count or for_each resource gains or loses instances
+	MoveChance   int // percentage chance that a resource moves to another module
+	OutputChance int // percentage chance that an output's value changes
+	DeleteChance int // percentage chance that a resource is deleted
+}
+
+// MutationOption is a function type for configuring MutationOptions
+type MutationOption func(*MutationOptions)
+
+// DefaultMutationOptions returns the default mix of changes
+func DefaultMutationOptions() MutationOptions {
+	return MutationOptions{
+		DriftChance:  20, // 20% chance
+		ScaleChance:  10, // 10% chance
+		MoveChance:   5,  // 5% chance
+		OutputChance: 30, // 30% chance
+		DeleteChance: 5,  // 5% chance
+	}
+}
+
+// WithDriftChance sets the percentage chance (0-100) that an instance's tags or size drift
+func WithDriftChance(percentage int) MutationOption {
+	return func(opts *MutationOptions) {
+		opts.DriftChance = clampPercentage(percentage)
+	}
+}
+
+// WithScaleChance sets the percentage chance (0-100) that a count or for_each resource gains or loses instances
+func WithScaleChance(percentage int) MutationOption {
+	return func(opts *MutationOptions) {
+		opts.ScaleChance = clampPercentage(percentage)
+	}
+}
+
+// WithMoveChance sets the percentage chance (0-100) that a resource moves to another module
+func WithMoveChance(percentage int) MutationOption {
+	return func(opts *MutationOptions) {
+		opts.MoveChance = clampPercentage(percentage)
+	}
+}
+
+// WithOutputChance sets the percentage chance (0-100) that an output's value changes
+func WithOutputChance(percentage int) MutationOption {
+	return func(opts *MutationOptions) {
+		opts.OutputChance = clampPercentage(percentage)
+	}
+}
+
+// WithDeleteChance sets the percentage chance (0-100) that a resource is deleted
+func WithDeleteChance(percentage int) MutationOption {
+	return func(opts *MutationOptions) {
+		opts.DeleteChance = clampPercentage(percentage)
+	}
+}
+
+func clampPercentage(percentage int) int {
+	return max(0, min(percentage, 100))
+}
+
+// Resizes that drift can apply to the size attributes of instances
+var driftSizes = map[string][]string{
+	"instance_type":  {"t3.micro", "t3.small", "t3.medium", "m5.large", "m5.xlarge", "c5.xlarge", "r5.large"},
+	"instance_class": {"db.t3.micro", "db.t3.small", "db.t3.medium", "db.r5.large", "db.r5.xlarge"},
+}
+
+// Mutate returns the state as it might look after the next apply: the serial
+// is bumped, the lineage is kept, and some resources and outputs are changed
+// according to the options. The given state is not modified, and need not
+// have been generated by statefaker.
+func Mutate(state *StateV4, opts ...MutationOption) *StateV4 {
+	options := DefaultMutationOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+	hit := func(chance int) bool { return rand.IntN(100) < chance }
+
+	next := *state
+	next.Serial++
+	next.Resources = nil
+	next.Outputs = make(map[string]json.RawMessage, len(state.Outputs))
+
+	addresses := make(map[string]bool)
+	modules := []string{""}
+	for _, resource := range state.Resources {
+		addresses[resourceAddress(resource)] = true
+		if !slices.Contains(modules, resource.Module) {
+			modules = append(modules, resource.Module)
+		}
+	}
+
+	deleted := make(map[string]bool)
+	moved := make(map[string]string)
+	for _, resource := range state.Resources {
+		address := resourceAddress(resource)
+		if hit(options.DeleteChance) {
+			deleted[address] = true
+			continue
+		}
+
+		resource.Instances = slices.Clone(resource.Instances)
+		if resource.Mode == "managed" {
+			for i := range resource.Instances {
+				if hit(options.DriftChance) {
+					resource.Instances[i].Attributes = driftAttributes(resource.Instances[i].Attributes)
+				}
+			}
+			if hit(options.ScaleChance) {
+				resource.Instances = scaleInstances(resource.Instances)
+			}
+		}
+
+		if hit(options.MoveChance) {
+			if movedResource := moveResource(resource, modules, addresses); movedResource != nil {
+				resource = *movedResource
+			}
+		}
+		if newAddress := resourceAddress(resource); newAddress != address {
+			moved[address] = newAddress
+		}
+
+		next.Resources = append(next.Resources, resource)
+	}
+
+	// Keep dependencies pointing at the resources they depended on
+	for i := range next.Resources {
+		for j := range next.Resources[i].Instances {
+			instance := &next.Resources[i].Instances[j]
+			var dependencies []string
+			for _, dependency := range instance.Dependencies {
+				if deleted[dependency] {
+					continue
+				}
+				if newAddress, ok := moved[dependency]; ok {
+					dependency = newAddress
+				}
+				dependencies = append(dependencies, dependency)
+			}
+			instance.Dependencies = dependencies
+		}
+	}
+
+	location := stateLocation(state)
+	for name, raw := range state.Outputs {
+		if hit(options.OutputChance) {
+			raw = changeOutput(raw, location)
+		}
+		next.Outputs[name] = raw
+	}
+
+	// Check results that cannot be decoded are kept as they are
+	var checkResults []CheckResultsV4
+	if len(state.CheckResults) > 0 && json.Unmarshal(state.CheckResults, &checkResults) == nil {
+		next.CheckResults, _ = json.Marshal(mutateCheckResults(checkResults, next.Resources, moved))
+	}
+
+	return &next
+}
+
+// driftAttributes changes a tag value, or resizes the instance, as happens
+// when infrastructure is edited outside of terraform or its configuration
+// is updated. Attributes that cannot be decoded are returned unchanged.
+func driftAttributes(raw json.RawMessage) json.RawMessage {
+	attributes, err := decodeValue(raw)
+	object, ok := attributes.(map[string]any)
+	if err != nil || !ok {
+		return raw
+	}
+
+	var drifts []func()
+	for name, sizes := range driftSizes {
+		if current, ok := object[name].(string); ok {
+			drifts = append(drifts, func() {
+				object[name] = nextSize(sizes, current)
+			})
+		}
+	}
+	if tags, ok := object["tags"].(map[string]any); ok {
+		drifts = append(drifts, func() {
+			key := faker.Word()
+			if len(tags) > 0 && rand.IntN(2) == 0 {
+				keys := slices.Sorted(maps.Keys(tags))
+				key = keys[rand.IntN(len(keys))]
+			}
+			tags[key] = faker.Word()
+			if tagsAll, ok := object["tags_all"].(map[string]any); ok {
+				tagsAll[key] = tags[key]
+			}
+		})
+	}
+	if len(drifts) == 0 {
+		return raw
+	}
+	drifts[rand.IntN(len(drifts))]()
+
+	b, err := json.Marshal(object)
+	if err != nil {
+		return raw
+	}
+	return b
+}
+
+// nextSize returns a different size to the current one
+func nextSize(sizes []string, current string) string {
+	for {
+		size := sizes[rand.IntN(len(sizes))]
+		if size != current {
+			return size
+		}
+	}
+}
+
+// scaleInstances adds or removes instances of a resource using count or
+// for_each, keeping at least one. Count indexes stay contiguous, so they are
+// added and removed at the end. A resource using neither is scaled up by
+// adding count or for_each to it, which keys its existing instance.
+func scaleInstances(instances []InstanceV4) []InstanceV4 {
+	// Deposed objects are scaled along with their current object
+	var current []InstanceV4
+	for _, instance := range instances {
+		if instance.Deposed == "" {
+			current = append(current, instance)
+		}
+	}
+	if len(current) == 0 {
+		return instances
+	}
+	if current[0].IndexKey == nil {
+		var key any = 0
+		if rand.IntN(2) == 0 {
+			key = fmt.Sprintf("%s-%s", faker.Word(), faker.Word())
+		}
+		for i := range instances {
+			instances[i].IndexKey = key
+		}
+		current[0].IndexKey = key
+	}
+	_, count := countIndex(current[0].IndexKey)
+
+	if len(current) > 1 && rand.IntN(2) == 0 {
+		remove := rand.IntN(len(current)-1) + 1
+		removed := make(map[string]bool, remove)
+		if count {
+			slices.SortFunc(current, compareInstances)
+			for _, instance := range current[len(current)-remove:] {
+				removed[instanceKey(instance.IndexKey)] = true
+			}
+		} else {
+			for _, i := range rand.Perm(len(current))[:remove] {
+				removed[instanceKey(current[i].IndexKey)] = true
+			}
+		}
+		return slices.DeleteFunc(instances, func(instance InstanceV4) bool {
+			return removed[instanceKey(instance.IndexKey)]
+		})
+	}
+
+	keys := make(map[string]bool, len(current))
+	identifiers := make(map[string]bool, len(current))
+	next := 0
+	for _, instance := range current {
+		keys[instanceKey(instance.IndexKey)] = true
+		if i, ok := countIndex(instance.IndexKey); ok {
+			next = max(next, i+1)
+		}
+		for _, identifier := range instanceIdentifiers(instance) {
+			identifiers[identifier] = true
+		}
+	}
+	for range rand.IntN(len(current)) + 1 {
+		instance := copyInstance(current[rand.IntN(len(current))], identifiers)
+		if count {
+			instance.IndexKey = next
+			next++
+		} else {
+			key := ""
+			for key == "" || keys[instanceKey(key)] {
+				key = fmt.Sprintf("%s-%s-%d", faker.Word(), faker.Word(), len(instances))
+			}
+			keys[instanceKey(key)] = true
+			instance.IndexKey = key
+		}
+		instances = append(instances, instance)
+	}
+	return instances
+}
+
+// instanceIdentifiers returns the id and arn attributes of an instance,
+// which identify its remote object
+func instanceIdentifiers(instance InstanceV4) []string {
+	var attributes struct {
+		ID  string `json:"id"`
+		ARN string `json:"arn"`
+	}
+	if len(instance.Attributes) > 0 {
+		json.Unmarshal(instance.Attributes, &attributes)
+	} else {
+		// Legacy flatmap attributes of states upgraded from terraform 0.11
+		attributes.ID, attributes.ARN = instance.AttributesFlat["id"], instance.AttributesFlat["arn"]
+	}
+
+	var identifiers []string
+	for _, identifier := range []string{attributes.ID, attributes.ARN} {
+		if identifier != "" {
+			identifiers = append(identifiers, identifier)
+		}
+	}
+	return identifiers
+}
+
+// copyInstance returns a new instance of the same resource as instance, for
+// a new remote object. Its id and arn are respelled wherever they appear in
+// its attributes and identity, to values that are not already taken.
+func copyInstance(instance InstanceV4, taken map[string]bool) InstanceV4 {
+	instance.Status = ""
+	instance.AttributesFlat = maps.Clone(instance.AttributesFlat)
+	for _, identifier := range instanceIdentifiers(instance) {
+		respelled := respell(identifier)
+		for taken[respelled] {
+			respelled = respell(identifier)
+		}
+		taken[respelled] = true
+
+		// An id is usually a segment of the arn, in which case the arn has
+		// already been respelled with it
+		replace := func(s string) string { return replaceSegment(s, identifier, respelled) }
+		instance.Attributes = replaceStrings(instance.Attributes, replace)
+		instance.Identity = replaceStrings(instance.Identity, replace)
+		for key, value := range instance.AttributesFlat {
+			instance.AttributesFlat[key] = replace(value)
+		}
+	}
+	return instance
+}
+
+// replaceSegment replaces old with new wherever it appears in s as a whole
+// segment of a path or arn, delimited by / or : or the ends of s
+func replaceSegment(s, old, new string) string {
+	isDelimiter := func(i int) bool { return s[i] == '/' || s[i] == ':' }
+	var replaced strings.Builder
+	start := 0
+	for from := 0; ; {
+		i := strings.Index(s[from:], old)
+		if i < 0 {
+			break
+		}
+		i += from
+		end := i + len(old)
+		if (i == 0 || isDelimiter(i-1)) && (end == len(s) || isDelimiter(end)) {
+			replaced.WriteString(s[start:i])
+			replaced.WriteString(new)
+			start, from = end, end
+		} else {
+			from = i + 1
+		}
+	}
+	replaced.WriteString(s[start:])
+	return replaced.String()
+}
+
+// respell returns an identifier with the characters of its last segment
+// replaced by random ones of the same kind, so that i-0a1b2c becomes
+// something like i-7f3e9d
+func respell(identifier string) string {
+	start := strings.LastIndexAny(identifier, "/:-_.") + 1
+	segment := []byte(identifier[start:])
+	if len(segment) == 0 {
+		return identifier + strconv.Itoa(rand.IntN(1000))
+	}
+
+	const hex = "0123456789abcdef"
+	isHex := !strings.ContainsFunc(string(segment), func(r rune) bool { return !strings.ContainsRune(hex, r) })
+	for i, c := range segment {
+		switch {
+		case isHex:
+			segment[i] = hex[rand.IntN(len(hex))]
+		case c >= '0' && c <= '9':
+			segment[i] = byte('0' + rand.IntN(10))
+		case c >= 'a' && c <= 'z':
+			segment[i] = byte('a' + rand.IntN(26))
+		case c >= 'A' && c <= 'Z':
+			segment[i] = byte('A' + rand.IntN(26))
+		}
+	}
+	return identifier[:start] + string(segment)
+}
+
+// replaceStrings returns JSON with replace applied to every string value
+// within it. JSON that cannot be decoded is returned unchanged.
+func replaceStrings(raw json.RawMessage, replace func(string) string) json.RawMessage {
+	if len(raw) == 0 {
+		return raw
+	}
+	value, err := decodeValue(raw)
+	if err != nil {
+		return raw
+	}
+
+	var walk func(value any) any
+	walk = func(value any) any {
+		switch value := value.(type) {
+		case string:
+			return replace(value)
+		case map[string]any:
+			for key, element := range value {
+				value[key] = walk(element)
+			}
+		case []any:
+			for i, element := range value {
+				value[i] = walk(element)
+			}
+		}
+		return value
+	}
+	return encodeValue(walk(value))
+}
+
+// moveResource returns the resource moved to a different module, as by a
+// moved block, or nil if there is nowhere it can move without colliding
+// with an existing address
+func moveResource(resource ResourceV4, modules []string, addresses map[string]bool) *ResourceV4 {
+	for _, i := range rand.Perm(len(modules)) {
+		if modules[i] == resource.Module {
+			continue
+		}
+		moved := resource
+		moved.Module = modules[i]
+		if addresses[resourceAddress(moved)] {
+			continue
+		}
+
+		// The provider configuration moves with the resource
+		provider := resource.Provider
+		if resource.Module != "" {
+			provider = strings.TrimPrefix(provider, resource.Module+".")
+		}
+		if moved.Module != "" {
+			provider = moved.Module + "." + provider
+		}
+		moved.Provider = provider
+
+		delete(addresses, resourceAddress(resource))
+		addresses[resourceAddress(moved)] = true
+		return &moved
+	}
+	return nil
+}
+
+// changeOutput gives an output a new value of the same type. Outputs of
+// complex types are replaced with a new generated output in the given
+// location.
+func changeOutput(raw json.RawMessage, location awsLocation) json.RawMessage {
+	var output OutputV4
+	if err := json.Unmarshal(raw, &output); err != nil {
+		return raw
+	}
+
+	var value any
+	switch typeKind(output.Type) {
+	case "string":
+		value = faker.Sentence()
+	case "number":
+		value = faker.UnixTime()
+	case "bool":
+		var current bool
+		json.Unmarshal(output.Value, &current)
+		value = !current
+	default:
+		generated, err := generateOutput(location, newCloudNameSequence(NameStyleKebab))
+		if err != nil {
+			return raw
+		}
+		var replacement OutputV4
+		if err := json.Unmarshal(generated, &replacement); err != nil {
+			return raw
+		}
+		output.Value, output.Type = replacement.Value, replacement.Type
+	}
+	if value != nil {
+		output.Value, _ = json.Marshal(value)
+	}
+
+	b, err := json.Marshal(output)
+	if err != nil {
+		return raw
+	}
+	return b
+}
+
+// mutateCheckResults follows resources that moved and drops the results of
+// resources and instances that no longer exist
+func mutateCheckResults(results []CheckResultsV4, resources []ResourceV4, moved map[string]string) []CheckResultsV4 {
+	objects := make(map[string]bool)
+	for _, resource := range resources {
+		address := resourceAddress(resource)
+		objects[address] = true
+		for _, instance := range resource.Instances {
+			objects[instanceAddress(address, instance)] = true
+		}
+	}
+
+	var next []CheckResultsV4
+	for _, result := range results {
+		if result.ObjectKind != "resource" {
+			next = append(next, result)
+			continue
+		}
+
+		configAddr := result.ConfigAddr
+		if newAddress, ok := moved[configAddr]; ok {
+			configAddr = newAddress
+		}
+		if !objects[configAddr] {
+			continue
+		}
+
+		var resultObjects []CheckResultsObjectV4
+		for _, object := range result.Objects {
+			object.ObjectAddr = configAddr + strings.TrimPrefix(object.ObjectAddr, result.ConfigAddr)
+			if objects[object.ObjectAddr] {
+				resultObjects = append(resultObjects, object)
+			}
+		}
+		result.ConfigAddr = configAddr
+		result.Objects = resultObjects
+		result.Status = aggregateCheckStatus(resultObjects)
+		next = append(next, result)
+	}
+	return next
+}
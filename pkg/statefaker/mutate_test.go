@@ -0,0 +1,189 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMutate(t *testing.T) {
+	state, err := NewFakeStateV4(WithResources(50), WithOutputs(10), WithMultiInstanceChance(50))
+	if err != nil {
+		t.Fatalf("failed to generate fake state: %v", err)
+	}
+	original, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("failed to marshal state: %v", err)
+	}
+
+	next := Mutate(state, WithDriftChance(50), WithScaleChance(50), WithMoveChance(20), WithOutputChance(50), WithDeleteChance(10))
+
+	if after, _ := json.Marshal(state); string(after) != string(original) {
+		t.Error("expected the original state to be left unchanged")
+	}
+	if next.Serial != state.Serial+1 {
+		t.Errorf("expected serial %d, got %d", state.Serial+1, next.Serial)
+	}
+	if next.Lineage != state.Lineage {
+		t.Errorf("expected lineage %s to be kept, got %s", state.Lineage, next.Lineage)
+	}
+
+	diff, err := Diff(state, next)
+	if err != nil {
+		t.Fatalf("failed to diff states: %v", err)
+	}
+	actions := make(map[ChangeAction]int)
+	for _, resource := range diff.Resources {
+		actions[resource.Action]++
+	}
+	if actions[ChangeChanged] == 0 || actions[ChangeRemoved] == 0 {
+		t.Errorf("expected changed and removed resources, got %v", actions)
+	}
+	if len(diff.Outputs) == 0 {
+		t.Error("expected some outputs to change")
+	}
+
+	addresses := make(map[string]bool)
+	for _, resource := range next.Resources {
+		address := resourceAddress(resource)
+		if addresses[address] {
+			t.Errorf("duplicate resource address %s", address)
+		}
+		addresses[address] = true
+
+		if resource.Module != "" && !strings.HasPrefix(resource.Provider, resource.Module+".provider[") {
+			t.Errorf("%s has provider %s outside its module", address, resource.Provider)
+		}
+		if len(resource.Instances) == 0 {
+			t.Errorf("%s was scaled to no instances", address)
+		}
+	}
+
+	// Managed resource dependencies always point at resources that exist
+	// when they did before
+	for _, resource := range next.Resources {
+		for _, instance := range resource.Instances {
+			for _, dependency := range instance.Dependencies {
+				if addresses[dependency] {
+					continue
+				}
+				for _, r := range state.Resources {
+					if resourceAddress(r) == dependency {
+						t.Errorf("%s depends on %s, which was deleted or moved", resourceAddress(resource), dependency)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestMutateTerraformState(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/count.tfstate")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var state StateV4
+	if err := json.Unmarshal(fixture, &state); err != nil {
+		t.Fatalf("failed to decode fixture: %v", err)
+	}
+
+	// Everything terraform wrote survives, including fields statefaker
+	// never generates
+	next := Mutate(&state, WithDriftChance(0), WithScaleChance(0), WithMoveChance(0), WithOutputChance(0), WithDeleteChance(0))
+	b, err := MarshalTerraform(next)
+	if err != nil {
+		t.Fatalf("failed to marshal state: %v", err)
+	}
+	expected := strings.Replace(string(fixture), `"serial": 7`, `"serial": 8`, 1)
+	if string(b) != expected {
+		t.Errorf("expected only the serial to change, got:\n%s", b)
+	}
+
+	for range 20 {
+		next := Mutate(&state, WithDriftChance(0), WithScaleChance(100), WithMoveChance(0), WithOutputChance(0), WithDeleteChance(0))
+		for _, resource := range next.Resources {
+			ids := make(map[string]bool)
+			var indexes []int
+			for _, instance := range resource.Instances {
+				var attributes struct {
+					ID  string `json:"id"`
+					ARN string `json:"arn"`
+				}
+				json.Unmarshal(instance.Attributes, &attributes)
+				if len(instance.AttributesFlat) > 0 {
+					attributes.ID, attributes.ARN = instance.AttributesFlat["id"], instance.AttributesFlat["arn"]
+				}
+				if ids[attributes.ID] {
+					t.Errorf("%s has more than one instance with id %s", resourceAddress(resource), attributes.ID)
+				}
+				ids[attributes.ID] = true
+				if attributes.ARN != "" && !strings.HasSuffix(attributes.ARN, "/"+attributes.ID) {
+					t.Errorf("%s has ARN %s that does not match its id %s", resourceAddress(resource), attributes.ARN, attributes.ID)
+				}
+
+				if i, ok := countIndex(instance.IndexKey); ok && instance.Deposed == "" {
+					indexes = append(indexes, i)
+				}
+			}
+
+			slices.Sort(indexes)
+			for i, index := range indexes {
+				if index != i {
+					t.Errorf("%s has count indexes %v, expected them to be contiguous", resourceAddress(resource), indexes)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestScaleInstancesWithoutIndexKey(t *testing.T) {
+	for range 20 {
+		instances := scaleInstances([]InstanceV4{
+			{Attributes: json.RawMessage(`{"id":"i-0a1b2c3d","arn":"arn:aws:ec2:us-east-1:123456789012:instance/i-0a1b2c3d"}`)},
+			{Deposed: "6f1e3c2a", Attributes: json.RawMessage(`{"id":"i-9f8e7d6c"}`)},
+		})
+		if len(instances) < 3 {
+			t.Fatalf("expected the resource to be scaled up, got %d instances", len(instances))
+		}
+
+		_, count := countIndex(instances[0].IndexKey)
+		keys := make(map[string]bool)
+		for _, instance := range instances {
+			if instance.IndexKey == nil {
+				t.Fatalf("expected every instance to have an index key, got %s", instance.Attributes)
+			}
+			if _, ok := countIndex(instance.IndexKey); ok != count {
+				t.Errorf("expected all index keys to use count or for_each, got %v and %v", instances[0].IndexKey, instance.IndexKey)
+			}
+			if instance.Deposed == "" {
+				key := instanceKey(instance.IndexKey)
+				if keys[key] {
+					t.Errorf("duplicate index key %s", key)
+				}
+				keys[key] = true
+			}
+		}
+		if instances[1].IndexKey != instances[0].IndexKey {
+			t.Errorf("expected the deposed object to keep the key of its current object %v, got %v", instances[0].IndexKey, instances[1].IndexKey)
+		}
+	}
+}
+
+func TestReplaceSegment(t *testing.T) {
+	for _, tc := range []struct {
+		s, expected string
+	}{
+		{"i-0a1b", "i-9f8e"},
+		{"arn:aws:ec2:us-east-1:123456789012:instance/i-0a1b", "arn:aws:ec2:us-east-1:123456789012:instance/i-9f8e"},
+		{"i-0a1b/i-0a1b:i-0a1b", "i-9f8e/i-9f8e:i-9f8e"},
+		{"i-0a1bc", "i-0a1bc"},
+		{"xi-0a1b/i-0a1b", "xi-0a1b/i-9f8e"},
+	} {
+		if got := replaceSegment(tc.s, "i-0a1b", "i-9f8e"); got != tc.expected {
+			t.Errorf("replaceSegment(%q): expected %q, got %q", tc.s, tc.expected, got)
+		}
+	}
+}
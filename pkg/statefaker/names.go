@@ -0,0 +1,179 @@
+package statefaker
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NameStyle selects the naming convention of generated resource, module and
+// output names. Every style produces valid terraform identifiers.
+type NameStyle int
+
+const (
+	// NameStyleSnake generates snake_case names like payments_sessions_prod
+	NameStyleSnake NameStyle = iota
+	// NameStyleKebab generates kebab-case names like payments-sessions-prod
+	NameStyleKebab
+	// NameStyleLegacy generates the hyphenated, timestamp suffixed names of
+	// older configurations, like payments-sessions-prod-1698273645
+	NameStyleLegacy
+	// NameStyleRandom generates unreadable names mixing case, digits,
+	// hyphens and underscores, like qX7_k-Ba2_1z
+	NameStyleRandom
+)
+
+// ParseNameStyle parses the name of a NameStyle as used on the command line
+func ParseNameStyle(name string) (NameStyle, error) {
+	switch name {
+	case "snake":
+		return NameStyleSnake, nil
+	case "kebab":
+		return NameStyleKebab, nil
+	case "legacy":
+		return NameStyleLegacy, nil
+	case "random":
+		return NameStyleRandom, nil
+	}
+	return NameStyleSnake, fmt.Errorf("unknown name style %q, expected snake, kebab, legacy or random", name)
+}
+
+// Parts of generated names. Resources are named <team>_<purpose>_<env>,
+// outputs <object>_<attribute> and modules after what they manage.
+var (
+	resourceNameParts = [][]string{
+		workspaceTeams,
+		{"primary", "assets", "logs", "sessions", "events", "uploads", "frontend", "backend", "workers", "metrics", "backups", "shared", "cache", "auth"},
+		workspaceEnvs,
+	}
+	outputNameParts = [][]string{
+		{"vpc", "bucket", "cluster", "database", "role", "queue", "api", "subnet", "instance", "function", "table", "zone", "key", "topic", "service", "user"},
+		{"id", "arn", "name", "endpoint", "ids", "arns", "url", "config", "names", "domain", "address", "port"},
+	}
+	moduleNameParts = [][]string{
+		{"network", "security", "database", "monitoring", "backup", "analytics", "compute", "storage", "identity", "logging", "encryption", "dns", "cdn", "cluster"},
+	}
+)
+
+// validIdentifier matches the names terraform accepts for resources,
+// modules and outputs
+var validIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// legacyEpoch is the earliest timestamp used as the suffix of legacy names
+const legacyEpoch = 1500000000
+
+// nameSequence generates names that never repeat within a state. Each name
+// is taken from a permutation of every combination of the name parts, so
+// consecutive names look unrelated, and once the combinations run out they
+// repeat with an increasing numeric suffix, like web_primary_prod_2. It
+// holds no record of the names it has generated, so generating millions of
+// names needs no more memory than generating one.
+type nameSequence struct {
+	parts      [][]string
+	style      NameStyle
+	size       int
+	multiplier int
+	offset     int
+	next       int
+	// epoch is the timestamp of the first legacy name
+	epoch int
+}
+
+func newNameSequence(parts [][]string, style NameStyle) *nameSequence {
+	size := 1
+	for _, part := range parts {
+		size *= len(part)
+	}
+
+	// Any multiplier coprime with the number of combinations visits each
+	// combination exactly once
+	multiplier := rand.IntN(size) + 1
+	for gcd(multiplier, size) != 1 {
+		multiplier = rand.IntN(size) + 1
+	}
+
+	return &nameSequence{
+		parts:      parts,
+		style:      style,
+		size:       size,
+		multiplier: multiplier,
+		offset:     rand.IntN(size),
+		epoch:      legacyEpoch + rand.IntN(200000000),
+	}
+}
+
+// name returns the next name in the sequence
+func (s *nameSequence) name() string {
+	n := s.next
+	s.next++
+	round, i := n/s.size, n%s.size
+
+	combination := (i*s.multiplier + s.offset) % s.size
+	words := make([]string, 0, len(s.parts)+1)
+	for _, part := range s.parts {
+		words = append(words, part[combination%len(part)])
+		combination /= len(part)
+	}
+
+	switch s.style {
+	case NameStyleKebab:
+		if round > 0 {
+			words = append(words, strconv.Itoa(round+1))
+		}
+		return strings.Join(words, "-")
+	case NameStyleLegacy:
+		// The timestamp alone keeps legacy names unique
+		return strings.Join(append(words, strconv.Itoa(s.epoch+n)), "-")
+	case NameStyleRandom:
+		return randomIdentifier() + "_" + strconv.FormatInt(int64(n), 36)
+	default:
+		if round > 0 {
+			words = append(words, strconv.Itoa(round+1))
+		}
+		return strings.Join(words, "_")
+	}
+}
+
+// randomIdentifier returns a short random identifier that starts with a
+// letter, as terraform requires
+func randomIdentifier() string {
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	const rest = letters + "0123456789_-"
+	b := make([]byte, rand.IntN(8)+3)
+	b[0] = letters[rand.IntN(len(letters))]
+	for i := 1; i < len(b); i++ {
+		b[i] = rest[rand.IntN(len(rest))]
+	}
+	return string(b)
+}
+
+// newCloudNameSequence returns the sequence of names given to resources by
+// their cloud provider, like bucket, role and cluster names. Those follow the
+// hyphenated conventions of the providers' own names, whatever the style of
+// the terraform identifiers, and only keep the timestamps of legacy names.
+func newCloudNameSequence(style NameStyle) *nameSequence {
+	if style != NameStyleLegacy {
+		style = NameStyleKebab
+	}
+	return newNameSequence(resourceNameParts, style)
+}
+
+// moduleAddresses generates between one and count module addresses for a
+// state, which resources are then spread across
+func moduleAddresses(style NameStyle, count int) []string {
+	names := newNameSequence(moduleNameParts, style)
+	addresses := make([]string, rand.IntN(count)+1)
+	for i := range addresses {
+		addresses[i] = "module." + names.name()
+	}
+	return addresses
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,91 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestNameSequence(t *testing.T) {
+	conventions := map[NameStyle]*regexp.Regexp{
+		NameStyleSnake:  regexp.MustCompile(`^[a-z]+_[a-z]+_[a-z]+(_\d+)?$`),
+		NameStyleKebab:  regexp.MustCompile(`^[a-z]+-[a-z]+-[a-z]+(-\d+)?$`),
+		NameStyleLegacy: regexp.MustCompile(`^[a-z]+-[a-z]+-[a-z]+-\d{10}$`),
+		NameStyleRandom: validIdentifier,
+	}
+
+	for style, convention := range conventions {
+		sequence := newNameSequence(resourceNameParts, style)
+		seen := make(map[string]bool)
+		for range 100000 {
+			name := sequence.name()
+			if seen[name] {
+				t.Fatalf("%d: name %s generated twice", style, name)
+			}
+			if !validIdentifier.MatchString(name) {
+				t.Fatalf("%d: name %s is not a valid identifier", style, name)
+			}
+			if !convention.MatchString(name) {
+				t.Fatalf("%d: name %s does not follow the naming convention", style, name)
+			}
+			seen[name] = true
+		}
+	}
+}
+
+func TestUniqueAddresses(t *testing.T) {
+	state, err := NewFakeStateV4(WithResources(1000), WithOutputs(500))
+	if err != nil {
+		t.Fatalf("failed to generate fake state: %v", err)
+	}
+
+	addresses := make(map[string]bool)
+	for _, resource := range state.Resources {
+		address := resourceAddress(resource)
+		if addresses[address] {
+			t.Errorf("duplicate resource address %s", address)
+		}
+		addresses[address] = true
+	}
+	if len(state.Outputs) != 500 {
+		t.Errorf("expected 500 distinct outputs, got %d", len(state.Outputs))
+	}
+}
+
+func TestCloudNames(t *testing.T) {
+	// Cloud-side names are hyphenated whatever the style of identifiers
+	kebab := regexp.MustCompile(`^(vnet-)?[a-z]+-[a-z]+-[a-z]+(-\d+)?(-role|-table|-vpc)?$`)
+	conventions := map[NameStyle]*regexp.Regexp{
+		NameStyleSnake:  kebab,
+		NameStyleRandom: kebab,
+		NameStyleLegacy: regexp.MustCompile(`^(vnet-)?[a-z]+-[a-z]+-[a-z]+-\d{10}(-role|-table|-vpc)?$`),
+	}
+	named := []string{"aws_iam_role", "aws_dynamodb_table", "google_compute_network", "azurerm_virtual_network"}
+
+	for style, convention := range conventions {
+		state, err := NewFakeStateV4(WithResources(300), WithNameStyle(style))
+		if err != nil {
+			t.Fatalf("failed to generate fake state: %v", err)
+		}
+		seen := make(map[string]bool)
+		for _, resource := range state.Resources {
+			if resource.Mode != "managed" || !slices.Contains(named, resource.Type) {
+				continue
+			}
+			for _, instance := range resource.Instances {
+				var attributes struct{ Name string }
+				if err := json.Unmarshal(instance.Attributes, &attributes); err != nil {
+					t.Fatalf("failed to decode attributes: %v", err)
+				}
+				if !convention.MatchString(attributes.Name) {
+					t.Errorf("%d: %s name %s does not follow the naming convention", style, resource.Type, attributes.Name)
+				}
+				if seen[attributes.Name] {
+					t.Errorf("%d: %s name %s generated twice", style, resource.Type, attributes.Name)
+				}
+				seen[attributes.Name] = true
+			}
+		}
+	}
+}
@@ -2,12 +2,32 @@ package statefaker
 
 // Options holds configuration options for generating fake state
 type Options struct {
-	NumOutputs          int
-	NumResources        int
-	MultiInstanceChance int // percentage chance (0-100) that a resource has multiple instances
-	MultiInstanceMin    int // minimum number of instances for multi-instance resources
-	MultiInstanceMax    int // maximum number of instances for multi-instance resources
-	ModuleChance        int // percentage chance (0-100) that a resource appears within a module
+	NumOutputs               int
+	NumResources             int
+	MultiInstanceChance      int // percentage chance (0-100) that a resource has multiple instances
+	MultiInstanceMin         int // minimum number of instances for multi-instance resources
+	MultiInstanceMax         int // maximum number of instances for multi-instance resources
+	ModuleChance             int // percentage chance (0-100) that a resource appears within a module
+	PrivateChance            int // percentage chance (0-100) that a managed resource instance has private data
+	PrivateSize              PrivateSize
+	ProviderAliasChance      int      // percentage chance (0-100) that a resource uses an aliased provider configuration
+	ProviderNamespaces       []string // registry namespaces providers are installed from
+	RegistryHosts            []string // registry hostnames providers are installed from
+	NumAccounts              int      // the number of AWS accounts resources are spread across by aliased provider configurations
+	NumRegions               int      // the number of AWS regions resources are spread across by aliased provider configurations
+	TerraformVersion         string   // the terraform version the state appears to be written by, empty for the format's default
+	Anomalies                []Anomaly
+	AnomalyChance            int    // percentage chance (0-100) that each enabled anomaly affects an eligible resource or output
+	RemoteStateChance        int    // percentage chance (0-100) that a workspace reads the outputs of other workspaces
+	Organization             string // the organization workspaces belong to, generated when empty
+	WorkspaceSize            WorkspaceSize
+	NameStyle                NameStyle
+	TagCountMin              int // minimum number of tags on a taggable resource, besides provider default tags
+	TagCountMax              int // maximum number of tags on a taggable resource, besides provider default tags
+	DefaultTagCount          int // number of tags the provider's default_tags applies to every taggable resource
+	HighCardinalityTagChance int // percentage chance (0-100) that a resource has tags whose values are unique to it
+	AttributeSize            int // average size in bytes of each instance's attributes, 0 to leave them at their natural size
+	AttributeSizeMax         int // largest size in bytes attributes are padded to, 0 for no limit
 }
 
 // Option is a function type for configuring Options
@@ -16,12 +36,25 @@ type Option func(*Options)
 // DefaultOptions returns the default configuration
 func DefaultOptions() Options {
 	return Options{
-		NumOutputs:          3,
-		NumResources:        3,
-		MultiInstanceChance: 10, // 10% chance
-		MultiInstanceMin:    3,
-		MultiInstanceMax:    50,
-		ModuleChance:        70, // 70% chance
+		NumOutputs:               3,
+		NumResources:             3,
+		MultiInstanceChance:      10, // 10% chance
+		MultiInstanceMin:         3,
+		MultiInstanceMax:         50,
+		ModuleChance:             70, // 70% chance
+		PrivateChance:            20, // 20% chance
+		PrivateSize:              PrivateSizeTypical,
+		ProviderAliasChance:      10, // 10% chance
+		ProviderNamespaces:       []string{"hashicorp"},
+		RegistryHosts:            []string{"registry.terraform.io"},
+		NumAccounts:              1,
+		NumRegions:               1,
+		AnomalyChance:            5,  // 5% chance
+		RemoteStateChance:        30, // 30% chance
+		TagCountMin:              2,
+		TagCountMax:              8,
+		DefaultTagCount:          3,
+		HighCardinalityTagChance: 10, // 10% chance
 	}
 }
 
@@ -85,9 +118,218 @@ func WithModuleChance(percentage int) Option {
 	}
 }
 
-// ApplyOptions applies the given options to the base configuration
+// WithPrivateChance sets the percentage chance (0-100) that a managed resource instance has private data
+func WithPrivateChance(percentage int) Option {
+	return func(opts *Options) {
+		if percentage < 0 {
+			percentage = 0
+		}
+		if percentage > 100 {
+			percentage = 100
+		}
+		opts.PrivateChance = percentage
+	}
+}
+
+// WithPrivateSize sets the size distribution of provider private data
+func WithPrivateSize(size PrivateSize) Option {
+	return func(opts *Options) {
+		opts.PrivateSize = size
+	}
+}
+
+// WithProviderAliasChance sets the percentage chance (0-100) that a resource uses an aliased provider configuration
+func WithProviderAliasChance(percentage int) Option {
+	return func(opts *Options) {
+		if percentage < 0 {
+			percentage = 0
+		}
+		if percentage > 100 {
+			percentage = 100
+		}
+		opts.ProviderAliasChance = percentage
+	}
+}
+
+// WithProviderNamespaces sets the registry namespaces providers are installed
+// from. Each provider in a state uses one of them, chosen at random, so a
+// namespace can be repeated to make it more likely.
+func WithProviderNamespaces(namespaces ...string) Option {
+	return func(opts *Options) {
+		if namespaces := trimList(namespaces); len(namespaces) > 0 {
+			opts.ProviderNamespaces = namespaces
+		}
+	}
+}
+
+// WithRegistryHosts sets the registry hostnames providers are installed from,
+// such as registry.terraform.io, registry.opentofu.org or a private registry.
+// Each provider in a state uses one of them, chosen at random.
+func WithRegistryHosts(hosts ...string) Option {
+	return func(opts *Options) {
+		if hosts := trimList(hosts); len(hosts) > 0 {
+			opts.RegistryHosts = hosts
+		}
+	}
+}
+
+// WithAccounts sets the number of AWS accounts resources are spread across by aliased provider configurations
+func WithAccounts(count int) Option {
+	return func(opts *Options) {
+		if count < 1 {
+			count = 1
+		}
+		opts.NumAccounts = count
+	}
+}
+
+// WithRegions sets the number of AWS regions resources are spread across by aliased provider configurations
+func WithRegions(count int) Option {
+	return func(opts *Options) {
+		if count < 1 {
+			count = 1
+		}
+		opts.NumRegions = count
+	}
+}
+
+// WithTerraformVersion sets the terraform version the state appears to be
+// written by. Only state features supported by that version are generated.
+// An empty version uses DefaultTerraformVersionV4 or DefaultTerraformVersionV3
+// depending on the state format being generated.
+func WithTerraformVersion(version string) Option {
+	return func(opts *Options) {
+		opts.TerraformVersion = version
+	}
+}
+
+// WithAnomalies enables pathological but valid data in the generated state
+func WithAnomalies(anomalies ...Anomaly) Option {
+	return func(opts *Options) {
+		opts.Anomalies = anomalies
+	}
+}
+
+// WithAnomalyChance sets the percentage chance (0-100) that each enabled anomaly affects an eligible resource or output
+func WithAnomalyChance(percentage int) Option {
+	return func(opts *Options) {
+		if percentage < 0 {
+			percentage = 0
+		}
+		if percentage > 100 {
+			percentage = 100
+		}
+		opts.AnomalyChance = percentage
+	}
+}
+
+// WithRemoteStateChance sets the percentage chance (0-100) that a workspace reads the outputs of other workspaces
+func WithRemoteStateChance(percentage int) Option {
+	return func(opts *Options) {
+		if percentage < 0 {
+			percentage = 0
+		}
+		if percentage > 100 {
+			percentage = 100
+		}
+		opts.RemoteStateChance = percentage
+	}
+}
+
+// WithOrganization sets the organization that generated workspaces belong to
+func WithOrganization(name string) Option {
+	return func(opts *Options) {
+		opts.Organization = name
+	}
+}
+
+// WithNameStyle sets the naming convention of generated resource, module and output names
+func WithNameStyle(style NameStyle) Option {
+	return func(opts *Options) {
+		opts.NameStyle = style
+	}
+}
+
+// WithTagCountMin sets the minimum number of tags on a taggable resource, besides provider default tags
+func WithTagCountMin(min int) Option {
+	return func(opts *Options) {
+		if min < 0 {
+			min = 0
+		}
+		opts.TagCountMin = min
+	}
+}
+
+// WithTagCountMax sets the maximum number of tags on a taggable resource, besides provider default tags
+func WithTagCountMax(max int) Option {
+	return func(opts *Options) {
+		if max < 0 {
+			max = 0
+		}
+		opts.TagCountMax = max
+	}
+}
+
+// WithDefaultTagCount sets the number of tags the provider's default_tags applies to every taggable resource
+func WithDefaultTagCount(count int) Option {
+	return func(opts *Options) {
+		if count < 0 {
+			count = 0
+		}
+		opts.DefaultTagCount = count
+	}
+}
+
+// WithHighCardinalityTagChance sets the percentage chance (0-100) that a resource has tags whose values are unique to it
+func WithHighCardinalityTagChance(percentage int) Option {
+	return func(opts *Options) {
+		if percentage < 0 {
+			percentage = 0
+		}
+		if percentage > 100 {
+			percentage = 100
+		}
+		opts.HighCardinalityTagChance = percentage
+	}
+}
+
+// WithAttributeSize sets the average size in bytes of each resource instance's
+// attributes. Instances are padded with realistic bulk, such as policy
+// documents, user_data scripts and long lists of CIDR blocks, to sizes spread
+// around the average. Resource types with nothing that grows large, like
+// VPCs and subnets, keep their natural size.
+func WithAttributeSize(bytes int) Option {
+	return func(opts *Options) {
+		if bytes < 0 {
+			bytes = 0
+		}
+		opts.AttributeSize = bytes
+	}
+}
+
+// WithAttributeSizeMax sets the largest size in bytes resource instance
+// attributes are padded to
+func WithAttributeSizeMax(bytes int) Option {
+	return func(opts *Options) {
+		if bytes < 0 {
+			bytes = 0
+		}
+		opts.AttributeSizeMax = bytes
+	}
+}
+
+// WithWorkspaceSize sets the distribution of the number of resources in each generated workspace
+func WithWorkspaceSize(size WorkspaceSize) Option {
+	return func(opts *Options) {
+		opts.WorkspaceSize = size
+	}
+}
+
+// ApplyOptions applies the given options on top of DefaultOptions, so that
+// options a caller does not set keep their defaults. Many options, such as
+// RegistryHosts and PrivateSize, have no usable zero value.
 func ApplyOptions(opts ...Option) Options {
-	options := Options{}
+	options := DefaultOptions()
 	for _, opt := range opts {
 		opt(&options)
 	}
@@ -96,6 +338,9 @@ func ApplyOptions(opts ...Option) Options {
 	if options.MultiInstanceMin > options.MultiInstanceMax {
 		options.MultiInstanceMax = options.MultiInstanceMin
 	}
+	if options.TagCountMin > options.TagCountMax {
+		options.TagCountMax = options.TagCountMin
+	}
 
 	return options
 }
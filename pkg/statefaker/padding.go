@@ -0,0 +1,799 @@
+package statefaker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/go-faker/faker/v4"
+)
+
+// attributeSizeSigma is the shape of the log-normal distribution of padded
+// attribute sizes. Most instances are within a factor of two of the average.
+const attributeSizeSigma = 0.5
+
+// sampleAttributeSize draws the target size in bytes of an instance's
+// attributes from a log-normal distribution with the given mean, capped at
+// max when max is positive
+func sampleAttributeSize(average, max int) int {
+	if average <= 0 {
+		return 0
+	}
+	mu := math.Log(float64(average)) - attributeSizeSigma*attributeSizeSigma/2
+	size := int(math.Exp(mu + attributeSizeSigma*rand.NormFloat64()))
+	if max > 0 && size > max {
+		size = max
+	}
+	return size
+}
+
+// attributePadders add realistic bulk to the attributes of a resource type,
+// growing their encoded size by about size bytes. Each padder only grows
+// attributes that its resource type has. Every type in the catalog has one,
+// and types with nothing that grows large in real states, like VPCs and
+// subnets, grow their tags.
+var attributePadders = map[string]func(ctx *attributeContext, attributes map[string]any, size int){
+	"aws_s3_bucket":                 padBucketPolicy,
+	"aws_iam_user":                  padTags,
+	"aws_iam_role":                  padInlinePolicies,
+	"aws_iam_policy":                padPolicy,
+	"aws_s3_bucket_policy":          padBucketPolicy,
+	"aws_kms_key":                   padKeyPolicy,
+	"aws_lambda_function":           padEnvironment,
+	"aws_instance":                  padUserData,
+	"aws_db_instance":               padTags,
+	"aws_dynamodb_table":            padSecondaryIndexes,
+	"aws_vpc":                       padTags,
+	"aws_subnet":                    padTags,
+	"aws_security_group":            padIngressRules,
+	"aws_route53_zone":              padZoneVPCs,
+	"aws_cloudfront_distribution":   padCacheBehaviors,
+	"aws_ecs_cluster":               padTags,
+	"aws_ecs_task_definition":       padContainerDefinitions,
+	"aws_eks_cluster":               padCertificateAuthority,
+	"aws_api_gateway_rest_api":      padOpenAPIBody,
+	"azurerm_resource_group":        padTags,
+	"azurerm_virtual_network":       padVirtualNetworkSubnets,
+	"azurerm_subnet":                padAddressPrefixes,
+	"azurerm_linux_virtual_machine": padCustomData,
+	"azurerm_storage_account":       padNetworkRules,
+	"azurerm_key_vault":             padAccessPolicies,
+	"google_compute_network":        padNetworkDescription,
+	"google_compute_instance":       padStartupScript,
+	"google_storage_bucket":         padLifecycleRules,
+	"google_project_iam_member":     padIAMCondition,
+	"google_container_cluster":      padMasterAuthorizedNetworks,
+	"google_sql_database_instance":  padAuthorizedNetworks,
+	"kubernetes_config_map":         padConfigMapData,
+	"kubernetes_deployment":         padLastAppliedConfiguration,
+	"kubernetes_manifest":           padManifestAnnotations,
+	"helm_release":                  padHelmValues,
+}
+
+// padAttributes grows the encoded size of attributes to about size bytes.
+// Attributes that are already larger are left alone.
+func padAttributes(ctx *attributeContext, resourceType string, attributes map[string]any, size int) error {
+	encoded, err := json.Marshal(attributes)
+	if err != nil {
+		return err
+	}
+	if len(encoded) >= size {
+		return nil
+	}
+
+	if pad, ok := attributePadders[resourceType]; ok {
+		pad(ctx, attributes, size-len(encoded))
+	}
+	return nil
+}
+
+// encodedStringSize returns the size of s once encoded as a JSON string,
+// without its enclosing quotes
+func encodedStringSize(s string) int {
+	b, _ := json.Marshal(s)
+	return len(b) - 2
+}
+
+func padPolicy(ctx *attributeContext, attributes map[string]any, size int) {
+	attributes["policy"] = generateSizedPolicyDocument(ctx, size)
+}
+
+func padInlinePolicies(ctx *attributeContext, attributes map[string]any, size int) {
+	// Each inline policy is limited to 10 KB, so large roles have many
+	var policies []map[string]any
+	for written := 0; written < size; {
+		policy := map[string]any{
+			"name":   fmt.Sprintf("%s-policy-%d", faker.Word(), len(policies)),
+			"policy": generateSizedPolicyDocument(ctx, min(size-written, 10240)),
+		}
+		policies = append(policies, policy)
+
+		b, _ := json.Marshal(policy)
+		written += len(b) + 1
+	}
+	attributes["inline_policy"] = policies
+}
+
+// generateSizedPolicyDocument generates an IAM policy document whose encoded
+// size as a JSON string is about size bytes
+func generateSizedPolicyDocument(ctx *attributeContext, size int) string {
+	return sizedPolicyDocument(nil, size, func(sid int) map[string]any {
+		return generatePolicyStatement(ctx, sid)
+	})
+}
+
+// sizedPolicyDocument generates a policy document made of statements
+// followed by as many statements from next as it takes for its encoded size
+// as a JSON string to grow by about size bytes
+func sizedPolicyDocument(statements []map[string]any, size int, next func(sid int) map[string]any) string {
+	for written := 0; written < size; {
+		statement := next(len(statements))
+		statements = append(statements, statement)
+
+		b, _ := json.Marshal(statement)
+		written += encodedStringSize(string(b)) + 1
+	}
+	return encodePolicyDocument(statements)
+}
+
+// bucketActions are the actions that bucket policies grant on prefixes
+var bucketActions = []string{"s3:GetObject", "s3:PutObject", "s3:DeleteObject", "s3:GetObjectVersion", "s3:PutObjectAcl", "s3:AbortMultipartUpload"}
+
+func padBucketPolicy(ctx *attributeContext, attributes map[string]any, size int) {
+	bucket, ok := attributes["bucket"].(string)
+	if !ok {
+		return
+	}
+	bucketARN := s3ARN(bucket)
+	statements := []map[string]any{
+		{
+			"Sid":       "DenyInsecureTransport",
+			"Effect":    "Deny",
+			"Principal": "*",
+			"Action":    "s3:*",
+			"Resource":  []string{bucketARN, bucketARN + "/*"},
+			"Condition": map[string]any{"Bool": map[string]string{"aws:SecureTransport": "false"}},
+		},
+	}
+	// Large bucket policies grant many roles access to their own prefixes
+	attributes["policy"] = sizedPolicyDocument(statements, size, func(sid int) map[string]any {
+		return map[string]any{
+			"Sid":    fmt.Sprintf("AllowPrefix%d", sid),
+			"Effect": "Allow",
+			"Principal": map[string]any{"AWS": ctx.refs("aws_iam_role", "arn", 1, 3, func() string {
+				return ctx.location.globalARN("iam", fmt.Sprintf("role/%s-role", ctx.name()))
+			})},
+			"Action":   pickStrings(bucketActions, rand.IntN(len(bucketActions))+1),
+			"Resource": fmt.Sprintf("%s/%s/%d/*", bucketARN, faker.Word(), sid),
+		}
+	})
+}
+
+// keyActions are the actions that key policies grant to users of a key
+var keyActions = []string{"kms:Decrypt", "kms:Encrypt", "kms:GenerateDataKey*", "kms:ReEncrypt*", "kms:DescribeKey", "kms:CreateGrant"}
+
+// defaultKeyPolicy returns the policy that KMS gives keys created without
+// one, which delegates access to the key to IAM
+func defaultKeyPolicy(location awsLocation) []map[string]any {
+	return []map[string]any{
+		{
+			"Sid":       "Enable IAM User Permissions",
+			"Effect":    "Allow",
+			"Principal": map[string]any{"AWS": location.globalARN("iam", "root")},
+			"Action":    "kms:*",
+			"Resource":  "*",
+		},
+	}
+}
+
+func padKeyPolicy(ctx *attributeContext, attributes map[string]any, size int) {
+	attributes["policy"] = sizedPolicyDocument(defaultKeyPolicy(ctx.location), size, func(sid int) map[string]any {
+		return map[string]any{
+			"Sid":    fmt.Sprintf("AllowUseOfTheKey%d", sid),
+			"Effect": "Allow",
+			"Principal": map[string]any{"AWS": ctx.refs("aws_iam_role", "arn", 1, 3, func() string {
+				return ctx.location.globalARN("iam", fmt.Sprintf("role/%s-role", ctx.name()))
+			})},
+			"Action":   pickStrings(keyActions, rand.IntN(len(keyActions))+1),
+			"Resource": "*",
+			"Condition": map[string]any{
+				"StringEquals": map[string]string{"kms:ViaService": fmt.Sprintf("%s.%s.amazonaws.com", pickStrings([]string{"s3", "rds", "secretsmanager", "sqs", "dynamodb"}, 1)[0], ctx.location.Region)},
+			},
+		}
+	})
+}
+
+// pickStrings returns count distinct strings chosen from choices
+func pickStrings(choices []string, count int) []string {
+	var picked []string
+	for _, i := range rand.Perm(len(choices))[:min(count, len(choices))] {
+		picked = append(picked, choices[i])
+	}
+	return picked
+}
+
+func padUserData(ctx *attributeContext, attributes map[string]any, size int) {
+	script := "#!/bin/bash\nset -euo pipefail\n"
+	written := encodedStringSize(script)
+	var lines []string
+	for written < size {
+		var line string
+		switch rand.IntN(4) {
+		case 0:
+			line = fmt.Sprintf("yum install -y %s\n", faker.Word())
+		case 1:
+			line = fmt.Sprintf("aws s3 cp s3://%s/%s.tar.gz /opt/%s/ --region %s\n", generateS3BucketName(), faker.Word(), faker.Word(), ctx.location.Region)
+		case 2:
+			line = fmt.Sprintf("echo \"%s=%s\" >> /etc/environment\n", strings.ToUpper(faker.Word()), faker.UUIDDigit())
+		default:
+			line = fmt.Sprintf("systemctl enable --now %s.service\n", faker.Word())
+		}
+		lines = append(lines, line)
+		written += encodedStringSize(line)
+	}
+	attributes["user_data"] = script + strings.Join(lines, "")
+}
+
+func padIngressRules(ctx *attributeContext, attributes map[string]any, size int) {
+	ingress, _ := attributes["ingress"].([]map[string]any)
+	for written := 0; written < size; {
+		// Allow lists of partner and office networks make for long rules
+		cidrBlocks := make([]string, rand.IntN(50)+10)
+		for i := range cidrBlocks {
+			cidrBlocks[i] = fmt.Sprintf("%d.%d.%d.0/24", rand.IntN(223)+1, rand.IntN(256), rand.IntN(256))
+		}
+		port := []int{22, 443, 5432, 8443}[rand.IntN(4)]
+		rule := map[string]any{
+			"description": faker.Sentence(),
+			"protocol":    "tcp",
+			"from_port":   port,
+			"to_port":     port,
+			"cidr_blocks": cidrBlocks,
+			"self":        false,
+		}
+		ingress = append(ingress, rule)
+
+		b, _ := json.Marshal(rule)
+		written += len(b) + 1
+	}
+	attributes["ingress"] = ingress
+}
+
+func padCertificateAuthority(ctx *attributeContext, attributes map[string]any, size int) {
+	// The data is a base64 encoded PEM bundle, so it grows by a third again
+	var bundle strings.Builder
+	for bundle.Len()*4/3 < size {
+		der := make([]byte, rand.IntN(512)+768)
+		for i := range der {
+			der[i] = byte(rand.IntN(256))
+		}
+		encoded := base64.StdEncoding.EncodeToString(der)
+		bundle.WriteString("-----BEGIN CERTIFICATE-----\n")
+		for len(encoded) > 64 {
+			bundle.WriteString(encoded[:64] + "\n")
+			encoded = encoded[64:]
+		}
+		bundle.WriteString(encoded + "\n-----END CERTIFICATE-----\n")
+	}
+	attributes["certificate_authority"] = []map[string]any{
+		{"data": base64.StdEncoding.EncodeToString([]byte(bundle.String()))},
+	}
+}
+
+func padEnvironment(ctx *attributeContext, attributes map[string]any, size int) {
+	variables := make(map[string]string)
+	if environment, ok := attributes["environment"].([]map[string]any); ok && len(environment) > 0 {
+		if existing, ok := environment[0]["variables"].(map[string]string); ok {
+			maps.Copy(variables, existing)
+		}
+	}
+	for written := 0; written < size; {
+		// Configuration is often passed to functions as JSON documents
+		key := fmt.Sprintf("%s_CONFIG_%d", strings.ToUpper(faker.Word()), len(variables))
+		config, _ := json.Marshal(map[string]any{
+			"endpoint": fmt.Sprintf("https://%s", faker.DomainName()),
+			"queue":    ctx.location.arn("sqs", ctx.name()),
+			"timeout":  rand.IntN(30) + 1,
+			"features": pickStrings([]string{"audit", "cache", "retry", "tracing", "batching", "compression"}, rand.IntN(6)+1),
+		})
+		variables[key] = string(config)
+		written += len(key) + encodedStringSize(string(config)) + 6
+	}
+	attributes["environment"] = []map[string]any{
+		{"variables": variables},
+	}
+}
+
+func padHelmValues(ctx *attributeContext, attributes map[string]any, size int) {
+	// Values are stored twice, as YAML and as JSON in the release metadata.
+	// The new values replace the chart's own, so they must be larger by half
+	// the padding.
+	current := 0
+	if yaml, ok := attributes["values"].([]string); ok && len(yaml) > 0 {
+		current = encodedStringSize(yaml[0])
+	}
+	values := generateHelmValues(current + size/2)
+	valuesJSON, _ := json.Marshal(values)
+	attributes["values"] = []string{renderYAML(values, 0)}
+	if metadata, ok := attributes["metadata"].([]map[string]any); ok && len(metadata) > 0 {
+		metadata[0]["values"] = string(valuesJSON)
+	}
+}
+
+func padConfigMapData(ctx *attributeContext, attributes map[string]any, size int) {
+	data, _ := attributes["data"].(map[string]string)
+	if data == nil {
+		data = make(map[string]string)
+		attributes["data"] = data
+	}
+	data[fmt.Sprintf("%s.yaml", faker.Word())] = renderYAML(generateHelmValues(size), 0)
+}
+
+// lastAppliedConfiguration generates the JSON that kubectl records in the
+// last-applied-configuration annotation of objects it has applied
+func lastAppliedConfiguration(apiVersion, kind, name string, size int) string {
+	configuration, _ := json.Marshal(map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]any{"name": name},
+		"spec":       generateHelmValues(size),
+	})
+	return string(configuration)
+}
+
+func padLastAppliedConfiguration(ctx *attributeContext, attributes map[string]any, size int) {
+	metadata, ok := attributes["metadata"].([]map[string]any)
+	if !ok || len(metadata) == 0 {
+		return
+	}
+	annotations, _ := metadata[0]["annotations"].(map[string]string)
+	if annotations == nil {
+		annotations = make(map[string]string)
+		metadata[0]["annotations"] = annotations
+	}
+	name, _ := metadata[0]["name"].(string)
+	annotations["kubectl.kubernetes.io/last-applied-configuration"] = lastAppliedConfiguration("apps/v1", "Deployment", name, size)
+}
+
+func padManifestAnnotations(ctx *attributeContext, attributes map[string]any, size int) {
+	object, ok := attributes["object"].(map[string]any)
+	if !ok {
+		return
+	}
+	value, ok := object["value"].(map[string]any)
+	if !ok {
+		return
+	}
+	metadata, ok := value["metadata"].(map[string]any)
+	if !ok {
+		return
+	}
+	apiVersion, _ := value["apiVersion"].(string)
+	kind, _ := value["kind"].(string)
+	name, _ := metadata["name"].(string)
+	metadata["annotations"] = map[string]any{
+		"kubectl.kubernetes.io/last-applied-configuration": lastAppliedConfiguration(apiVersion, kind, name, size),
+	}
+	// The type of a dynamically typed attribute follows its value
+	object["type"] = ctyTypeOf(value)
+}
+
+func padContainerDefinitions(ctx *attributeContext, attributes map[string]any, size int) {
+	definitions, ok := attributes["container_definitions"].(string)
+	if !ok {
+		return
+	}
+	var containers []map[string]any
+	if err := json.Unmarshal([]byte(definitions), &containers); err != nil || len(containers) == 0 {
+		return
+	}
+	// Applications are configured through the environment of their container
+	environment, _ := containers[0]["environment"].([]any)
+	for written := 0; written < size; {
+		variable := map[string]string{
+			"name":  fmt.Sprintf("%s_%s_%d", strings.ToUpper(faker.Word()), strings.ToUpper(faker.Word()), len(environment)),
+			"value": fmt.Sprintf("https://%s/%s?timeout=%d", faker.DomainName(), faker.Word(), rand.IntN(30)+1),
+		}
+		environment = append(environment, variable)
+
+		b, _ := json.Marshal(variable)
+		written += encodedStringSize(string(b)) + 1
+	}
+	containers[0]["environment"] = environment
+
+	b, _ := json.Marshal(containers)
+	attributes["container_definitions"] = string(b)
+}
+
+func padOpenAPIBody(ctx *attributeContext, attributes map[string]any, size int) {
+	name, ok := attributes["name"].(string)
+	if !ok {
+		return
+	}
+	// APIs imported from an OpenAPI definition keep it in body, with an
+	// integration for each method
+	paths := make(map[string]any)
+	for written := 0; written < size; {
+		functionARN := ctx.refs("aws_lambda_function", "arn", 1, 1, func() string {
+			return ctx.location.arn("lambda", fmt.Sprintf("function:%s-lambda", ctx.name()))
+		})[0]
+		path := fmt.Sprintf("/%s/%s/{id}", faker.Word(), faker.Word())
+		if _, ok := paths[path]; ok {
+			path = fmt.Sprintf("%s/%d", path, len(paths))
+		}
+		item := map[string]any{}
+		for _, method := range pickStrings([]string{"get", "put", "post", "delete", "patch"}, rand.IntN(3)+1) {
+			item[method] = map[string]any{
+				"operationId": fmt.Sprintf("%s%s", method, strings.ToUpper(faker.Word())),
+				"parameters":  []map[string]any{{"name": "id", "in": "path", "required": true, "schema": map[string]string{"type": "string"}}},
+				"responses":   map[string]any{"200": map[string]string{"description": faker.Sentence()}},
+				"x-amazon-apigateway-integration": map[string]any{
+					"type":       "aws_proxy",
+					"httpMethod": "POST",
+					"uri":        fmt.Sprintf("arn:aws:apigateway:%s:lambda:path/2015-03-31/functions/%s/invocations", ctx.location.Region, functionARN),
+				},
+			}
+		}
+		paths[path] = item
+
+		b, _ := json.Marshal(item)
+		written += encodedStringSize(path) + encodedStringSize(string(b)) + 4
+	}
+
+	body, _ := json.Marshal(map[string]any{
+		"openapi": "3.0.1",
+		"info":    map[string]string{"title": name, "version": "1.0"},
+		"paths":   paths,
+	})
+	attributes["body"] = string(body)
+}
+
+func padCustomData(ctx *attributeContext, attributes map[string]any, size int) {
+	// Custom data is cloud-init configuration, base64 encoded so that it
+	// grows by a third again
+	var files, commands strings.Builder
+	for (files.Len()+commands.Len())*4/3 < size {
+		fmt.Fprintf(&files, "  - path: /etc/%s/%s.conf\n    permissions: '0644'\n    content: |\n      %s=%s\n      endpoint=https://%s\n", faker.Word(), faker.Word(), strings.ToUpper(faker.Word()), faker.UUIDDigit(), faker.DomainName())
+		fmt.Fprintf(&commands, "  - systemctl restart %s\n", faker.Word())
+	}
+	config := "#cloud-config\npackage_update: true\nwrite_files:\n" + files.String() + "runcmd:\n" + commands.String()
+	attributes["custom_data"] = base64.StdEncoding.EncodeToString([]byte(config))
+}
+
+func padVirtualNetworkSubnets(ctx *attributeContext, attributes map[string]any, size int) {
+	resourceGroup, _ := attributes["resource_group_name"].(string)
+	network, _ := attributes["name"].(string)
+	addressSpace, _ := attributes["address_space"].([]string)
+	prefix := "10.0"
+	if len(addressSpace) > 0 {
+		prefix = strings.TrimSuffix(addressSpace[0], ".0.0/16")
+	}
+
+	// Large networks have a subnet, with its own network security group, for
+	// each workload
+	subnets, _ := attributes["subnet"].([]map[string]any)
+	for written := 0; written < size; {
+		name := fmt.Sprintf("snet-%s-%d", faker.Word(), len(subnets))
+		subnet := map[string]any{
+			"id":                                ctx.azure.resourceID(resourceGroup, "Microsoft.Network", fmt.Sprintf("virtualNetworks/%s/subnets/%s", network, name)),
+			"name":                              name,
+			"address_prefixes":                  []string{fmt.Sprintf("%s.%d.%d/28", prefix, len(subnets)/16%256, len(subnets)%16*16)},
+			"security_group":                    ctx.azure.resourceID(resourceGroup, "Microsoft.Network", "networkSecurityGroups/nsg-"+name),
+			"route_table_id":                    "",
+			"default_outbound_access_enabled":   true,
+			"delegation":                        []map[string]any{},
+			"private_endpoint_network_policies": "Disabled",
+			"private_link_service_network_policies_enabled": true,
+			"service_endpoints":                             pickStrings([]string{"Microsoft.Storage", "Microsoft.KeyVault", "Microsoft.Sql"}, rand.IntN(3)),
+			"service_endpoint_policy_ids":                   []string{},
+		}
+		subnets = append(subnets, subnet)
+
+		b, _ := json.Marshal(subnet)
+		written += len(b) + 1
+	}
+	attributes["subnet"] = subnets
+}
+
+func padAccessPolicies(ctx *attributeContext, attributes map[string]any, size int) {
+	// Vaults shared by many applications grant each of them access
+	policies, _ := attributes["access_policy"].([]map[string]any)
+	for written := 0; written < size; {
+		policy := map[string]any{
+			"tenant_id":               ctx.azure.TenantID,
+			"object_id":               faker.UUIDHyphenated(),
+			"application_id":          "",
+			"key_permissions":         pickStrings([]string{"Get", "List", "Create", "Delete", "Encrypt", "Decrypt", "WrapKey", "UnwrapKey"}, rand.IntN(8)+1),
+			"secret_permissions":      pickStrings([]string{"Get", "List", "Set", "Delete", "Recover", "Backup", "Restore"}, rand.IntN(7)+1),
+			"certificate_permissions": pickStrings([]string{"Get", "List", "Create", "Import", "Update"}, rand.IntN(5)),
+			"storage_permissions":     []string{},
+		}
+		policies = append(policies, policy)
+
+		b, _ := json.Marshal(policy)
+		written += len(b) + 1
+	}
+	attributes["access_policy"] = policies
+}
+
+func padStartupScript(ctx *attributeContext, attributes map[string]any, size int) {
+	metadata, _ := attributes["metadata"].(map[string]string)
+	if metadata == nil {
+		return
+	}
+	script := "#!/bin/bash\nset -euo pipefail\n"
+	written := encodedStringSize(script)
+	var lines []string
+	for written < size {
+		var line string
+		switch rand.IntN(4) {
+		case 0:
+			line = fmt.Sprintf("apt-get install -y %s\n", faker.Word())
+		case 1:
+			line = fmt.Sprintf("gsutil cp gs://%s-%s/%s.tar.gz /opt/%s/\n", ctx.google.Project, generateS3BucketName(), faker.Word(), faker.Word())
+		case 2:
+			line = fmt.Sprintf("echo \"%s=$(curl -s -H 'Metadata-Flavor: Google' http://metadata.google.internal/computeMetadata/v1/instance/attributes/%s)\" >> /etc/environment\n", strings.ToUpper(faker.Word()), faker.Word())
+		default:
+			line = fmt.Sprintf("systemctl enable --now %s.service\n", faker.Word())
+		}
+		lines = append(lines, line)
+		written += encodedStringSize(line)
+	}
+	metadata["startup-script"] = script + strings.Join(lines, "")
+}
+
+func padLifecycleRules(ctx *attributeContext, attributes map[string]any, size int) {
+	// Buckets shared by many teams expire or archive each team's prefixes
+	// on their own schedule
+	rules, _ := attributes["lifecycle_rule"].([]map[string]any)
+	for written := 0; written < size; {
+		prefixes := make([]string, rand.IntN(40)+10)
+		for i := range prefixes {
+			prefixes[i] = fmt.Sprintf("%s/%s/%d/", faker.Word(), faker.Word(), i)
+		}
+		action := map[string]any{"type": "Delete", "storage_class": ""}
+		if rand.IntN(2) == 0 {
+			action = map[string]any{"type": "SetStorageClass", "storage_class": []string{"NEARLINE", "COLDLINE", "ARCHIVE"}[rand.IntN(3)]}
+		}
+		rule := map[string]any{
+			"action": []map[string]any{action},
+			"condition": []map[string]any{
+				{
+					"age":            []int{7, 30, 90, 365}[rand.IntN(4)],
+					"matches_prefix": prefixes,
+					"matches_suffix": []string{},
+					"with_state":     []string{"ANY", "LIVE", "ARCHIVED"}[rand.IntN(3)],
+				},
+			},
+		}
+		rules = append(rules, rule)
+
+		b, _ := json.Marshal(rule)
+		written += len(b) + 1
+	}
+	attributes["lifecycle_rule"] = rules
+}
+
+func padAuthorizedNetworks(ctx *attributeContext, attributes map[string]any, size int) {
+	settings, _ := attributes["settings"].([]map[string]any)
+	if len(settings) == 0 {
+		return
+	}
+	ipConfiguration, _ := settings[0]["ip_configuration"].([]map[string]any)
+	if len(ipConfiguration) == 0 {
+		return
+	}
+	// Instances reached over their public address allow lists of office and
+	// partner networks
+	var networks []map[string]any
+	for written := 0; written < size; {
+		network := map[string]any{
+			"name":            fmt.Sprintf("%s-%d", faker.Word(), len(networks)),
+			"value":           fmt.Sprintf("%d.%d.%d.0/24", rand.IntN(223)+1, rand.IntN(256), rand.IntN(256)),
+			"expiration_time": "",
+		}
+		networks = append(networks, network)
+
+		b, _ := json.Marshal(network)
+		written += len(b) + 1
+	}
+	ipConfiguration[0]["ipv4_enabled"] = true
+	ipConfiguration[0]["authorized_networks"] = networks
+}
+
+func padTags(ctx *attributeContext, attributes map[string]any, size int) {
+	tags, _ := attributes["tags"].(map[string]string)
+	if tags == nil {
+		tags = make(map[string]string)
+		attributes["tags"] = tags
+	}
+	// AWS resources record their tags again in tags_all
+	tagsAll, _ := attributes["tags_all"].(map[string]string)
+	copies := 1
+	if tagsAll != nil {
+		copies = 2
+	}
+	// Organizations that track ownership, cost allocation and compliance in
+	// tags give resources many namespaced tags with long values
+	for written := 0; written < size; {
+		key := fmt.Sprintf("%s:%s:%s-%d", faker.Word(), faker.Word(), faker.Word(), len(tags))
+		value := faker.Paragraph()
+		value = value[:min(len(value), 256)]
+		tags[key] = value
+		if tagsAll != nil {
+			tagsAll[key] = value
+		}
+		written += (encodedStringSize(key) + encodedStringSize(value) + 6) * copies
+	}
+}
+
+func padSecondaryIndexes(ctx *attributeContext, attributes map[string]any, size int) {
+	// Single table designs query by many keys, with a global secondary index
+	// projecting the attributes each access pattern needs
+	definitions, _ := attributes["attribute"].([]map[string]any)
+	indexes, _ := attributes["global_secondary_index"].([]map[string]any)
+	for written := 0; written < size; {
+		hashKey := fmt.Sprintf("gsi%dpk", len(indexes)+1)
+		rangeKey := fmt.Sprintf("gsi%dsk", len(indexes)+1)
+		projected := make([]string, rand.IntN(60)+20)
+		for i := range projected {
+			projected[i] = fmt.Sprintf("%s%s%d", faker.Word(), strings.ToUpper(faker.Word()[:1]), i)
+		}
+		index := map[string]any{
+			"name":               fmt.Sprintf("%s-%s-index", faker.Word(), faker.Word()),
+			"hash_key":           hashKey,
+			"range_key":          rangeKey,
+			"projection_type":    "INCLUDE",
+			"non_key_attributes": projected,
+			"read_capacity":      0,
+			"write_capacity":     0,
+		}
+		indexes = append(indexes, index)
+		definitions = append(definitions, map[string]any{"name": hashKey, "type": "S"}, map[string]any{"name": rangeKey, "type": "S"})
+
+		b, _ := json.Marshal(index)
+		written += len(b) + 60
+	}
+	attributes["attribute"] = definitions
+	attributes["global_secondary_index"] = indexes
+}
+
+func padZoneVPCs(ctx *attributeContext, attributes map[string]any, size int) {
+	// Private zones shared across an organization are associated with the
+	// VPC of every account that resolves them
+	vpcs, _ := attributes["vpc"].([]map[string]any)
+	for written := 0; written < size; {
+		vpc := map[string]any{
+			"vpc_id":     fmt.Sprintf("vpc-%s", faker.UUIDDigit()[:17]),
+			"vpc_region": ctx.location.Region,
+		}
+		vpcs = append(vpcs, vpc)
+
+		b, _ := json.Marshal(vpc)
+		written += len(b) + 1
+	}
+	attributes["vpc"] = vpcs
+}
+
+func padCacheBehaviors(ctx *attributeContext, attributes map[string]any, size int) {
+	origin := fmt.Sprintf("S3-%s", generateS3BucketName())
+	if origins, ok := attributes["origin"].([]map[string]any); ok && len(origins) > 0 {
+		if id, ok := origins[0]["origin_id"].(string); ok {
+			origin = id
+		}
+	}
+	// Sites served from one distribution have a cache behavior for each
+	// path with its own caching and methods
+	behaviors, _ := attributes["ordered_cache_behavior"].([]map[string]any)
+	for written := 0; written < size; {
+		behavior := map[string]any{
+			"path_pattern":             fmt.Sprintf("/%s/%s/*", faker.Word(), faker.Word()),
+			"target_origin_id":         origin,
+			"viewer_protocol_policy":   []string{"redirect-to-https", "https-only", "allow-all"}[rand.IntN(3)],
+			"allowed_methods":          []string{"GET", "HEAD", "OPTIONS"},
+			"cached_methods":           []string{"GET", "HEAD"},
+			"compress":                 rand.IntN(2) == 0,
+			"min_ttl":                  0,
+			"default_ttl":              []int{0, 3600, 86400}[rand.IntN(3)],
+			"max_ttl":                  31536000,
+			"cache_policy_id":          faker.UUIDHyphenated(),
+			"origin_request_policy_id": faker.UUIDHyphenated(),
+			"function_association": []map[string]any{
+				{"event_type": "viewer-request", "function_arn": ctx.location.globalARN("cloudfront", fmt.Sprintf("function/%s-rewrite", ctx.name()))},
+			},
+		}
+		behaviors = append(behaviors, behavior)
+
+		b, _ := json.Marshal(behavior)
+		written += len(b) + 1
+	}
+	attributes["ordered_cache_behavior"] = behaviors
+}
+
+func padAddressPrefixes(ctx *attributeContext, attributes map[string]any, size int) {
+	// Subnets that outgrow their range are extended with more prefixes
+	prefixes, _ := attributes["address_prefixes"].([]string)
+	for written := 0; written < size; {
+		prefix := fmt.Sprintf("10.%d.%d.%d/28", rand.IntN(256), rand.IntN(256), rand.IntN(16)*16)
+		prefixes = append(prefixes, prefix)
+		written += len(prefix) + 3
+	}
+	attributes["address_prefixes"] = prefixes
+}
+
+func padNetworkRules(ctx *attributeContext, attributes map[string]any, size int) {
+	resourceGroup, _ := attributes["resource_group_name"].(string)
+	// Accounts locked down to their clients allow lists of networks and the
+	// subnets of every application that reads them
+	var ipRules, subnetIDs []string
+	for written := 0; written < size; {
+		if rand.IntN(2) == 0 {
+			rule := fmt.Sprintf("%d.%d.%d.0/24", rand.IntN(223)+1, rand.IntN(256), rand.IntN(256))
+			ipRules = append(ipRules, rule)
+			written += len(rule) + 3
+			continue
+		}
+		id := ctx.azure.resourceID(resourceGroup, "Microsoft.Network", fmt.Sprintf("virtualNetworks/vnet-%s/subnets/snet-%s", ctx.name(), faker.Word()))
+		subnetIDs = append(subnetIDs, id)
+		written += encodedStringSize(id) + 3
+	}
+	attributes["network_rules"] = []map[string]any{
+		{
+			"default_action":             "Deny",
+			"bypass":                     []string{"AzureServices"},
+			"ip_rules":                   ipRules,
+			"virtual_network_subnet_ids": subnetIDs,
+			"private_link_access":        []map[string]any{},
+		},
+	}
+}
+
+func padNetworkDescription(ctx *attributeContext, attributes map[string]any, size int) {
+	// Shared networks document the ranges and teams they are allocated to
+	var description strings.Builder
+	for encodedStringSize(description.String()) < size {
+		fmt.Fprintf(&description, "10.%d.0.0/16: %s (%s)\n", rand.IntN(256), faker.Sentence(), strings.ToLower(faker.Word()))
+	}
+	attributes["description"] = description.String()
+}
+
+func padIAMCondition(ctx *attributeContext, attributes map[string]any, size int) {
+	// Conditional grants limit a role to the resources named in a long
+	// common expression language condition
+	var clauses []string
+	for written := 0; written < size; {
+		clause := fmt.Sprintf("resource.name.startsWith(\"projects/%s/%s/%s-%s\")", ctx.google.Project, []string{"buckets", "secrets", "topics", "datasets"}[rand.IntN(4)], faker.Word(), faker.Word())
+		clauses = append(clauses, clause)
+		written += encodedStringSize(clause) + 4
+	}
+	attributes["condition"] = []map[string]any{
+		{
+			"title":       fmt.Sprintf("%s-resources", faker.Word()),
+			"description": faker.Sentence(),
+			"expression":  strings.Join(clauses, " || "),
+		},
+	}
+}
+
+func padMasterAuthorizedNetworks(ctx *attributeContext, attributes map[string]any, size int) {
+	// Clusters with public endpoints only allow office, VPN and CI networks
+	// to reach their control plane
+	var blocks []map[string]any
+	for written := 0; written < size; {
+		block := map[string]any{
+			"cidr_block":   fmt.Sprintf("%d.%d.%d.0/24", rand.IntN(223)+1, rand.IntN(256), rand.IntN(256)),
+			"display_name": fmt.Sprintf("%s-%s-%d", faker.Word(), faker.Word(), len(blocks)),
+		}
+		blocks = append(blocks, block)
+
+		b, _ := json.Marshal(block)
+		written += len(b) + 1
+	}
+	attributes["master_authorized_networks_config"] = []map[string]any{
+		{"cidr_blocks": blocks, "gcp_public_cidrs_access_enabled": false},
+	}
+}
@@ -0,0 +1,149 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAttributeSize(t *testing.T) {
+	const average, max = 64 * 1024, 96 * 1024
+
+	state, err := NewFakeStateV4(
+		WithResources(150),
+		WithMultiInstanceChance(0),
+		WithAttributeSize(average),
+		WithAttributeSizeMax(max),
+	)
+	if err != nil {
+		t.Fatalf("failed to generate fake state: %v", err)
+	}
+
+	total, count := 0, 0
+	for _, resource := range state.Resources {
+		// Helm releases and config maps can be larger than the maximum
+		// before any padding
+		if resource.Type == "helm_release" || resource.Type == "kubernetes_config_map" {
+			continue
+		}
+		for _, instance := range resource.Instances {
+			// Padding stops at the first piece that reaches the target, so
+			// allow for one piece beyond the maximum
+			if len(instance.Attributes) > max+4096 {
+				t.Errorf("%s attributes are %d bytes, more than the maximum of %d", resource.Type, len(instance.Attributes), max)
+			}
+			total += len(instance.Attributes)
+			count++
+
+			var attributes map[string]any
+			if err := json.Unmarshal(instance.Attributes, &attributes); err != nil {
+				t.Fatalf("failed to decode attributes: %v", err)
+			}
+			// Policy documents and the like are JSON encoded within a string
+			for _, name := range []string{"policy", "container_definitions", "body"} {
+				if document, ok := attributes[name].(string); ok && !json.Valid([]byte(document)) {
+					t.Errorf("%s %s is not a JSON document", resource.Type, name)
+				}
+			}
+		}
+	}
+
+	// The maximum trims the top of the distribution, pulling the mean below
+	// the average
+	if mean := total / count; mean < average/2 || mean > average*5/4 {
+		t.Errorf("expected attributes to average about %d bytes, got %d", average, mean)
+	}
+}
+
+func TestAttributeSizeDefault(t *testing.T) {
+	state, err := NewFakeStateV4(WithResources(30))
+	if err != nil {
+		t.Fatalf("failed to generate fake state: %v", err)
+	}
+	for _, resource := range state.Resources {
+		if !strings.HasPrefix(resource.Type, "aws_") {
+			continue
+		}
+		for _, instance := range resource.Instances {
+			if len(instance.Attributes) > 16*1024 {
+				t.Errorf("expected unpadded attributes by default, %s attributes are %d bytes", resource.Type, len(instance.Attributes))
+			}
+		}
+	}
+}
+
+func TestPaddingStaysInSchema(t *testing.T) {
+	g, err := newGenerator(ApplyOptions())
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+
+	for _, rt := range resourceTypes {
+		ctx := newAttributeContext(g.locations, g.locations.primary(), g.references, g.tagger, g.cloudNames)
+		attributes := rt.Attributes(ctx)
+		schema := slices.Collect(maps.Keys(attributes))
+		if err := padAttributes(ctx, rt.Name, attributes, 64*1024); err != nil {
+			t.Fatalf("failed to pad %s attributes: %v", rt.Name, err)
+		}
+		b, err := json.Marshal(attributes)
+		if err != nil {
+			t.Fatalf("failed to marshal %s attributes: %v", rt.Name, err)
+		}
+		if _, ok := attributePadders[rt.Name]; ok && len(b) < 32*1024 {
+			t.Errorf("expected %s to be padded towards 64 KB, got %d bytes", rt.Name, len(b))
+		}
+		if strings.HasPrefix(rt.Name, "aws_") {
+			continue
+		}
+
+		for name := range attributes {
+			if !slices.Contains(schema, name) {
+				t.Errorf("padding added %s, which %s does not have", name, rt.Name)
+			}
+		}
+		if strings.Contains(string(b), "arn:aws:") {
+			t.Errorf("padding gave %s an AWS document", rt.Name)
+		}
+	}
+}
+
+func TestPaddingReachesSize(t *testing.T) {
+	const size = 64 * 1024
+
+	g, err := newGenerator(ApplyOptions())
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+
+	for _, rt := range resourceTypes {
+		if _, ok := attributePadders[rt.Name]; !ok {
+			t.Errorf("%s has no padder", rt.Name)
+			continue
+		}
+		for range 5 {
+			ctx := newAttributeContext(g.locations, g.locations.primary(), g.references, g.tagger, g.cloudNames)
+			attributes := rt.Attributes(ctx)
+			unpadded, err := json.Marshal(attributes)
+			if err != nil {
+				t.Fatalf("failed to marshal %s attributes: %v", rt.Name, err)
+			}
+			if err := padAttributes(ctx, rt.Name, attributes, size); err != nil {
+				t.Fatalf("failed to pad %s attributes: %v", rt.Name, err)
+			}
+			b, err := json.Marshal(attributes)
+			if err != nil {
+				t.Fatalf("failed to marshal %s attributes: %v", rt.Name, err)
+			}
+
+			// Padding stops at the first piece that reaches the target
+			if len(b) < size*15/16 {
+				t.Errorf("expected %s to be padded to about %d bytes, got %d", rt.Name, size, len(b))
+			}
+			if len(unpadded) < size && len(b) > size+8*1024 {
+				t.Errorf("expected %s to be padded to about %d bytes, got %d", rt.Name, size, len(b))
+			}
+		}
+	}
+}
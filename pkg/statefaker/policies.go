@@ -0,0 +1,305 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/go-faker/faker/v4"
+)
+
+// policyTarget is a resource type that IAM policy statements grant access to
+type policyTarget struct {
+	ResourceType string
+	Actions      []string
+	// ARNs returns the resources a statement names for an instance of the
+	// resource type
+	ARNs func(attributes map[string]any) []string
+	// Fallback generates an ARN when the state has no instance to refer to
+	Fallback func(ctx *attributeContext) string
+}
+
+// attributeARN returns the "arn" attribute, with any suffixes appended
+func attributeARN(suffixes ...string) func(attributes map[string]any) []string {
+	return func(attributes map[string]any) []string {
+		arn, _ := attributes["arn"].(string)
+		arns := []string{arn}
+		for _, suffix := range suffixes {
+			arns = append(arns, arn+suffix)
+		}
+		return arns
+	}
+}
+
+var policyTargets = []policyTarget{
+	{
+		ResourceType: "aws_s3_bucket",
+		Actions:      []string{"s3:GetObject", "s3:PutObject", "s3:DeleteObject", "s3:ListBucket", "s3:GetBucketLocation"},
+		ARNs:         attributeARN("/*"),
+		Fallback:     func(*attributeContext) string { return s3ARN(generateS3BucketName()) },
+	},
+	{
+		ResourceType: "aws_dynamodb_table",
+		Actions:      []string{"dynamodb:GetItem", "dynamodb:PutItem", "dynamodb:Query", "dynamodb:Scan", "dynamodb:UpdateItem", "dynamodb:BatchWriteItem"},
+		ARNs:         attributeARN("/index/*"),
+		Fallback: func(ctx *attributeContext) string {
+			return ctx.location.arn("dynamodb", fmt.Sprintf("table/%s-table", ctx.name()))
+		},
+	},
+	{
+		ResourceType: "aws_kms_key",
+		Actions:      []string{"kms:Decrypt", "kms:Encrypt", "kms:GenerateDataKey", "kms:DescribeKey"},
+		ARNs:         attributeARN(),
+		Fallback: func(ctx *attributeContext) string {
+			return ctx.location.arn("kms", fmt.Sprintf("key/%s", faker.UUIDHyphenated()))
+		},
+	},
+	{
+		ResourceType: "aws_lambda_function",
+		Actions:      []string{"lambda:InvokeFunction", "lambda:GetFunction"},
+		ARNs:         attributeARN(":*"),
+		Fallback: func(ctx *attributeContext) string {
+			return ctx.location.arn("lambda", fmt.Sprintf("function:%s-lambda", ctx.name()))
+		},
+	},
+	{
+		ResourceType: "aws_iam_role",
+		Actions:      []string{"iam:PassRole", "sts:AssumeRole"},
+		ARNs:         attributeARN(),
+		Fallback: func(ctx *attributeContext) string {
+			return ctx.location.globalARN("iam", fmt.Sprintf("role/%s-role", ctx.name()))
+		},
+	},
+	{
+		ResourceType: "aws_ecs_cluster",
+		Actions:      []string{"ecs:RunTask", "ecs:DescribeTasks", "ecs:UpdateService", "ecs:DescribeServices"},
+		ARNs:         attributeARN(),
+		Fallback: func(ctx *attributeContext) string {
+			return ctx.location.arn("ecs", fmt.Sprintf("cluster/%s-cluster", ctx.name()))
+		},
+	},
+	{
+		ResourceType: "aws_eks_cluster",
+		Actions:      []string{"eks:DescribeCluster", "eks:ListNodegroups", "eks:AccessKubernetesApi"},
+		ARNs:         attributeARN(),
+		Fallback: func(ctx *attributeContext) string {
+			return ctx.location.arn("eks", fmt.Sprintf("cluster/%s-eks", ctx.name()))
+		},
+	},
+	{
+		ResourceType: "aws_db_instance",
+		Actions:      []string{"rds:DescribeDBInstances", "rds-db:connect", "rds:CreateDBSnapshot"},
+		ARNs:         attributeARN(),
+		Fallback: func(ctx *attributeContext) string {
+			return ctx.location.arn("rds", fmt.Sprintf("db:%s-db", ctx.name()))
+		},
+	},
+}
+
+// generatePolicyStatement generates an IAM policy statement granting access
+// to resources in the state, or made up ones when there are none to refer to
+func generatePolicyStatement(ctx *attributeContext, sid int) map[string]any {
+	target := policyTargets[rand.IntN(len(policyTargets))]
+
+	var resources []string
+	for _, attributes := range ctx.pick(target.ResourceType, 1, 3) {
+		resources = append(resources, target.ARNs(attributes)...)
+	}
+	if len(resources) == 0 {
+		resources = []string{target.Fallback(ctx)}
+	}
+
+	effect := "Allow"
+	condition := rand.IntN(6)
+	if condition == 0 {
+		effect = "Deny"
+	}
+	service, _, _ := strings.Cut(target.Actions[0], ":")
+	statement := map[string]any{
+		"Sid":      fmt.Sprintf("%s%s%d", effect, strings.ToUpper(service[:1])+service[1:], sid),
+		"Effect":   effect,
+		"Action":   pickStrings(target.Actions, rand.IntN(len(target.Actions))+1),
+		"Resource": resources,
+	}
+	// A single action is often written as a string
+	if actions := statement["Action"].([]string); len(actions) == 1 && rand.IntN(2) == 0 {
+		statement["Action"] = actions[0]
+	}
+	switch condition {
+	case 0:
+		statement["Condition"] = map[string]any{
+			"Bool": map[string]string{"aws:SecureTransport": "false"},
+		}
+	case 1:
+		statement["Condition"] = map[string]any{
+			"StringEquals": map[string]string{"aws:RequestedRegion": ctx.location.Region},
+		}
+	case 2:
+		statement["Condition"] = map[string]any{
+			"StringEquals": map[string]string{"aws:PrincipalAccount": ctx.location.AccountID},
+		}
+	}
+	return statement
+}
+
+// generatePolicyDocument generates an IAM policy document with between
+// low and high statements, encoded as JSON the way it is stored in state
+func generatePolicyDocument(ctx *attributeContext, low, high int) string {
+	statements := make([]map[string]any, rand.IntN(high-low+1)+low)
+	for i := range statements {
+		statements[i] = generatePolicyStatement(ctx, i)
+	}
+	return encodePolicyDocument(statements)
+}
+
+func encodePolicyDocument(statements []map[string]any) string {
+	document, _ := json.Marshal(map[string]any{
+		"Version":   "2012-10-17",
+		"Statement": statements,
+	})
+	return string(document)
+}
+
+// assumeRolePrincipals are the services that commonly assume roles
+var assumeRolePrincipals = []string{
+	"ec2.amazonaws.com", "lambda.amazonaws.com", "ecs-tasks.amazonaws.com", "eks.amazonaws.com",
+	"states.amazonaws.com", "events.amazonaws.com", "codebuild.amazonaws.com",
+}
+
+// generateAssumeRolePolicy generates the trust policy of an IAM role, which
+// lets services, other accounts or a federated identity provider assume it
+func generateAssumeRolePolicy(location awsLocation) string {
+	var statements []map[string]any
+	statements = append(statements, map[string]any{
+		"Effect":    "Allow",
+		"Action":    "sts:AssumeRole",
+		"Principal": map[string]any{"Service": pickStrings(assumeRolePrincipals, rand.IntN(2)+1)},
+	})
+	switch rand.IntN(4) {
+	case 0:
+		// Cross account access, guarded by an external ID
+		statements = append(statements, map[string]any{
+			"Effect":    "Allow",
+			"Action":    "sts:AssumeRole",
+			"Principal": map[string]any{"AWS": fmt.Sprintf("arn:aws:iam::%012d:root", rand.Int64N(1e12))},
+			"Condition": map[string]any{
+				"StringEquals": map[string]string{"sts:ExternalId": faker.UUIDDigit()},
+			},
+		})
+	case 1:
+		// GitHub Actions deploying through OIDC federation
+		statements = append(statements, map[string]any{
+			"Effect":    "Allow",
+			"Action":    "sts:AssumeRoleWithWebIdentity",
+			"Principal": map[string]any{"Federated": location.globalARN("iam", "oidc-provider/token.actions.githubusercontent.com")},
+			"Condition": map[string]any{
+				"StringEquals": map[string]string{"token.actions.githubusercontent.com:aud": "sts.amazonaws.com"},
+				"StringLike":   map[string]string{"token.actions.githubusercontent.com:sub": fmt.Sprintf("repo:%s/%s:*", faker.Word(), faker.Word())},
+			},
+		})
+	}
+	return encodePolicyDocument(statements)
+}
+
+// generateBucketPolicy generates the resource policy of an S3 bucket,
+// granting roles and users in the state access to it
+func generateBucketPolicy(ctx *attributeContext, bucketARN string) string {
+	statements := []map[string]any{
+		{
+			"Sid":       "DenyInsecureTransport",
+			"Effect":    "Deny",
+			"Principal": "*",
+			"Action":    "s3:*",
+			"Resource":  []string{bucketARN, bucketARN + "/*"},
+			"Condition": map[string]any{"Bool": map[string]string{"aws:SecureTransport": "false"}},
+		},
+	}
+
+	principals := ctx.refs("aws_iam_role", "arn", 1, 3, func() string {
+		return ctx.location.globalARN("iam", fmt.Sprintf("role/%s-role", ctx.name()))
+	})
+	if users := ctx.pick("aws_iam_user", 0, 2); len(users) > 0 {
+		for _, user := range users {
+			principals = append(principals, user["arn"].(string))
+		}
+	}
+	statements = append(statements, map[string]any{
+		"Sid":       "AllowReadWrite",
+		"Effect":    "Allow",
+		"Principal": map[string]any{"AWS": principals},
+		"Action":    pickStrings(policyTargets[0].Actions, rand.IntN(4)+2),
+		"Resource":  []string{bucketARN, bucketARN + "/*"},
+	})
+	if rand.IntN(2) == 0 {
+		// CloudFront origin access
+		distribution := ctx.ref("aws_cloudfront_distribution", "arn", func() string {
+			return ctx.location.globalARN("cloudfront", fmt.Sprintf("distribution/E%s", strings.ToUpper(faker.UUIDDigit()[:13])))
+		})
+		statements = append(statements, map[string]any{
+			"Sid":       "AllowCloudFrontServicePrincipal",
+			"Effect":    "Allow",
+			"Principal": map[string]any{"Service": "cloudfront.amazonaws.com"},
+			"Action":    "s3:GetObject",
+			"Resource":  bucketARN + "/*",
+			"Condition": map[string]any{"StringEquals": map[string]string{"AWS:SourceArn": distribution}},
+		})
+	}
+	return encodePolicyDocument(statements)
+}
+
+// generateContainerDefinitions generates the container_definitions of an
+// ECS task definition: a JSON array of between one and four containers
+func generateContainerDefinitions(ctx *attributeContext, family string) string {
+	containers := make([]map[string]any, rand.IntN(4)+1)
+	for i := range containers {
+		name := family
+		if i > 0 {
+			name = []string{"sidecar", "envoy", "datadog-agent", "log-router", "migrations"}[rand.IntN(5)]
+		}
+		port := []int{80, 3000, 8080, 9000}[rand.IntN(4)]
+
+		environment := []map[string]string{}
+		for range rand.IntN(6) {
+			environment = append(environment, map[string]string{
+				"name":  strings.ToUpper(faker.Word()) + "_" + strings.ToUpper(faker.Word()),
+				"value": faker.Word(),
+			})
+		}
+		var secrets []map[string]string
+		for range rand.IntN(3) {
+			secrets = append(secrets, map[string]string{
+				"name":      strings.ToUpper(faker.Word()) + "_PASSWORD",
+				"valueFrom": ctx.location.arn("secretsmanager", fmt.Sprintf("secret:%s/%s-%s", family, faker.Word(), faker.UUIDDigit()[:6])),
+			})
+		}
+
+		container := map[string]any{
+			"name":      name,
+			"image":     fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com/%s:%s", ctx.location.AccountID, ctx.location.Region, name, faker.UUIDDigit()[:7]),
+			"cpu":       []int{0, 128, 256}[rand.IntN(3)],
+			"essential": i == 0,
+			"portMappings": []map[string]any{
+				{"containerPort": port, "hostPort": port, "protocol": "tcp"},
+			},
+			"environment": environment,
+			"mountPoints": []any{},
+			"volumesFrom": []any{},
+			"logConfiguration": map[string]any{
+				"logDriver": "awslogs",
+				"options": map[string]string{
+					"awslogs-group":         fmt.Sprintf("/ecs/%s", family),
+					"awslogs-region":        ctx.location.Region,
+					"awslogs-stream-prefix": name,
+				},
+			},
+		}
+		if len(secrets) > 0 {
+			container["secrets"] = secrets
+		}
+		containers[i] = container
+	}
+
+	definitions, _ := json.Marshal(containers)
+	return string(definitions)
+}
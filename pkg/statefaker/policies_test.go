@@ -0,0 +1,80 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPolicyDocuments(t *testing.T) {
+	state, err := NewFakeStateV4(WithResources(300))
+	if err != nil {
+		t.Fatalf("failed to generate fake state: %v", err)
+	}
+
+	arns := make(map[string]bool)
+	for _, resource := range state.Resources {
+		for _, instance := range resource.Instances {
+			var attributes map[string]any
+			if err := json.Unmarshal(instance.Attributes, &attributes); err != nil {
+				t.Fatalf("failed to decode attributes: %v", err)
+			}
+			if arn, ok := attributes["arn"].(string); ok {
+				arns[arn] = true
+			}
+		}
+	}
+
+	documents, references := 0, 0
+	for _, resource := range state.Resources {
+		for _, instance := range resource.Instances {
+			var attributes map[string]any
+			if err := json.Unmarshal(instance.Attributes, &attributes); err != nil {
+				t.Fatalf("failed to decode attributes: %v", err)
+			}
+
+			for _, name := range []string{"policy", "assume_role_policy"} {
+				encoded, ok := attributes[name].(string)
+				if !ok {
+					continue
+				}
+				documents++
+
+				var document struct {
+					Version   string           `json:"Version"`
+					Statement []map[string]any `json:"Statement"`
+				}
+				if err := json.Unmarshal([]byte(encoded), &document); err != nil {
+					t.Errorf("%s %s is not a JSON policy document: %v", resourceAddress(resource), name, err)
+					continue
+				}
+				if document.Version != "2012-10-17" || len(document.Statement) == 0 {
+					t.Errorf("%s %s is not a valid policy document: %s", resourceAddress(resource), name, encoded)
+				}
+				for _, statement := range document.Statement {
+					resources, _ := statement["Resource"].([]any)
+					for _, resource := range resources {
+						if arns[strings.TrimSuffix(resource.(string), "/*")] {
+							references++
+						}
+					}
+				}
+			}
+
+			if encoded, ok := attributes["container_definitions"].(string); ok {
+				documents++
+				var containers []map[string]any
+				if err := json.Unmarshal([]byte(encoded), &containers); err != nil || len(containers) == 0 {
+					t.Errorf("%s container_definitions is not a JSON array of containers: %s", resourceAddress(resource), encoded)
+				}
+			}
+		}
+	}
+
+	if documents == 0 {
+		t.Fatal("expected resources with policy documents or container definitions")
+	}
+	if references == 0 {
+		t.Error("expected policy statements to refer to resources in the state")
+	}
+}
@@ -0,0 +1,121 @@
+package statefaker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/go-faker/faker/v4"
+)
+
+// PrivateSize selects the size distribution of generated provider private data
+type PrivateSize int
+
+const (
+	// PrivateSizeTypical mostly generates the small blobs written by SDKv2
+	// providers, with the occasional plugin framework private state
+	PrivateSizeTypical PrivateSize = iota
+	// PrivateSizeLarge generates plenty of plugin framework private state, some
+	// of it several kilobytes in size
+	PrivateSizeLarge
+	// PrivateSizePathological generates mostly multi-kilobyte blobs, some of
+	// them hundreds of kilobytes in size
+	PrivateSizePathological
+)
+
+// privateSizeWeights holds the relative weights of small, medium and huge
+// private blobs for each PrivateSize
+var privateSizeWeights = map[PrivateSize][3]int{
+	PrivateSizeTypical:      {90, 10, 0},
+	PrivateSizeLarge:        {50, 40, 10},
+	PrivateSizePathological: {20, 30, 50},
+}
+
+// ParsePrivateSize parses the name of a PrivateSize as used on the command line
+func ParsePrivateSize(name string) (PrivateSize, error) {
+	switch name {
+	case "typical":
+		return PrivateSizeTypical, nil
+	case "large":
+		return PrivateSizeLarge, nil
+	case "pathological":
+		return PrivateSizePathological, nil
+	}
+	return PrivateSizeTypical, fmt.Errorf("unknown private size %q, expected typical, large or pathological", name)
+}
+
+// sdkTimeoutsKey is the key SDKv2 providers store resource timeouts under
+const sdkTimeoutsKey = "e2bfb730-ecaa-11e6-8f88-34363bc7c4c0"
+
+// generatePrivate generates the base64 encoded JSON private data that
+// providers store alongside a resource instance
+func generatePrivate(size PrivateSize) (string, error) {
+	weights := privateSizeWeights[size]
+	pick := rand.IntN(weights[0] + weights[1] + weights[2])
+
+	var private any
+	switch {
+	case pick < weights[0]:
+		private = generateSDKPrivate()
+	case pick < weights[0]+weights[1]:
+		private = generateFrameworkPrivate(rand.IntN(1024) + 128)
+	default:
+		private = generateFrameworkPrivate(rand.IntN(256*1024-4096) + 4096)
+	}
+
+	b, err := json.Marshal(private)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// generateSDKPrivate generates the private data written by SDKv2 providers,
+// which is the schema version and sometimes the configured timeouts
+func generateSDKPrivate() map[string]any {
+	private := map[string]any{
+		"schema_version": fmt.Sprint(rand.IntN(3)),
+	}
+	if rand.IntN(3) == 0 {
+		// Timeouts are stored as nanoseconds
+		timeouts := map[string]int64{
+			"create": 600000000000,
+		}
+		for _, operation := range []string{"update", "delete"} {
+			if rand.IntN(2) == 0 {
+				timeouts[operation] = int64(rand.IntN(60)+1) * 60000000000
+			}
+		}
+		private[sdkTimeoutsKey] = timeouts
+	}
+	return private
+}
+
+// generateFrameworkPrivate generates plugin framework private state of
+// roughly the given size in bytes. The framework stores a JSON document for
+// each provider defined key, as bytes, so each value is a base64 string.
+func generateFrameworkPrivate(size int) map[string][]byte {
+	etag, _ := json.Marshal(fmt.Sprintf("\"%s\"", faker.UUIDDigit()))
+
+	var history []map[string]any
+	written := 0
+	for written < size {
+		entry := map[string]any{
+			"operation_id": faker.UUIDHyphenated(),
+			"timestamp":    faker.Timestamp(),
+			"status":       []string{"SUCCEEDED", "FAILED", "IN_PROGRESS"}[rand.IntN(3)],
+			"message":      faker.Sentence(),
+		}
+		history = append(history, entry)
+		// Roughly the encoded size of each entry, which grows by a third
+		// again once base64 encoded
+		written += (150 + len(entry["message"].(string))) * 4 / 3
+	}
+	operations, _ := json.Marshal(history)
+
+	return map[string][]byte{
+		"etag":       etag,
+		"operations": operations,
+	}
+}
@@ -0,0 +1,93 @@
+package statefaker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestGeneratePrivate(t *testing.T) {
+	for _, size := range []PrivateSize{PrivateSizeTypical, PrivateSizeLarge, PrivateSizePathological} {
+		huge := 0
+		for range 100 {
+			private, err := generatePrivate(size)
+			if err != nil {
+				t.Fatalf("failed to generate private data: %v", err)
+			}
+			b, err := base64.StdEncoding.DecodeString(private)
+			if err != nil {
+				t.Fatalf("private data is not base64: %v", err)
+			}
+			var decoded map[string]json.RawMessage
+			if err := json.Unmarshal(b, &decoded); err != nil {
+				t.Fatalf("private data is not a JSON object: %v", err)
+			}
+
+			switch {
+			case decoded["schema_version"] != nil:
+				// SDKv2 private data, with timeouts in nanoseconds
+				if timeouts, ok := decoded[sdkTimeoutsKey]; ok {
+					var durations map[string]int64
+					if err := json.Unmarshal(timeouts, &durations); err != nil || durations["create"] == 0 {
+						t.Errorf("unexpected SDK timeouts %s", timeouts)
+					}
+				}
+			case decoded["etag"] != nil && decoded["operations"] != nil:
+				// Plugin framework private state, a JSON document per key
+				// encoded as bytes
+				var framework map[string][]byte
+				if err := json.Unmarshal(b, &framework); err != nil {
+					t.Fatalf("framework private state is not a map of bytes: %v", err)
+				}
+				for key, value := range framework {
+					if !json.Valid(value) {
+						t.Errorf("framework private state %s is not JSON: %s", key, value)
+					}
+				}
+			default:
+				t.Errorf("unexpected private data %s", b)
+			}
+			if len(b) >= 4096 {
+				huge++
+			}
+		}
+
+		switch size {
+		case PrivateSizeTypical:
+			if huge > 0 {
+				t.Errorf("expected no huge private data for typical sizes, got %d", huge)
+			}
+		case PrivateSizePathological:
+			// Half of pathological private data is huge
+			if huge < 30 {
+				t.Errorf("expected most pathological private data to be huge, got %d of 100", huge)
+			}
+		}
+	}
+}
+
+func TestApplyOptionsDefaults(t *testing.T) {
+	if options := ApplyOptions(); !reflect.DeepEqual(options, DefaultOptions()) {
+		t.Errorf("expected the default options, got %+v", options)
+	}
+
+	// Options that are not set keep their defaults
+	options := ApplyOptions(WithPrivateChance(100))
+	defaults := DefaultOptions()
+	if options.PrivateChance != 100 || options.PrivateSize != defaults.PrivateSize || options.NumResources != defaults.NumResources {
+		t.Errorf("expected defaults apart from the private chance, got %+v", options)
+	}
+
+	state, err := NewFakeStateV4(WithResources(50), WithPrivateChance(100))
+	if err != nil {
+		t.Fatalf("failed to generate fake state: %v", err)
+	}
+	for _, resource := range state.Resources {
+		for _, instance := range resource.Instances {
+			if (instance.Private != "") != (resource.Mode == "managed") {
+				t.Errorf("%s is %s but has private data %q", resourceAddress(resource), resource.Mode, instance.Private)
+			}
+		}
+	}
+}
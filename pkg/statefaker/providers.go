@@ -0,0 +1,140 @@
+package statefaker
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+)
+
+// providerAliases are the aliases given to additional provider configurations
+var providerAliases = []string{
+	"west", "east", "secondary", "replica", "dr", "shared_services",
+	"us_east_1", "us_west_2", "eu_west_1", "ap_southeast_2", "management",
+}
+
+var (
+	// validNamespace matches registry namespaces, which are letters, digits,
+	// dashes and underscores that start and end with a letter or digit
+	validNamespace = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9_-]*[a-zA-Z0-9])?$`)
+	// validRegistryHost matches registry hostnames, with an optional port
+	validRegistryHost = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*(:[0-9]+)?$`)
+)
+
+// ParseProviderNamespaces parses a comma separated list of registry
+// namespaces, such as "hashicorp, acme"
+func ParseProviderNamespaces(s string) ([]string, error) {
+	return parseList(s, "namespace", func(namespace string) bool {
+		return validNamespace.MatchString(namespace)
+	})
+}
+
+// ParseRegistryHosts parses a comma separated list of registry hostnames,
+// such as "registry.terraform.io, registry.opentofu.org". Hostnames are
+// case insensitive and are returned in lower case.
+func ParseRegistryHosts(s string) ([]string, error) {
+	return parseList(strings.ToLower(s), "registry hostname", func(host string) bool {
+		return validRegistryHost.MatchString(host)
+	})
+}
+
+// parseList splits a comma separated list, trimming spaces from each item and
+// ignoring empty items, and checks each item is valid
+func parseList(s, kind string, valid func(string) bool) ([]string, error) {
+	items := trimList(strings.Split(s, ","))
+	if len(items) == 0 {
+		return nil, fmt.Errorf("expected at least one %s", kind)
+	}
+	for _, item := range items {
+		if !valid(item) {
+			return nil, fmt.Errorf("invalid %s %q", kind, item)
+		}
+	}
+	return items, nil
+}
+
+// trimList trims spaces from each item, dropping any that are empty
+func trimList(items []string) []string {
+	var trimmed []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			trimmed = append(trimmed, item)
+		}
+	}
+	return trimmed
+}
+
+// providerSource is the source address of a provider, such as
+// registry.terraform.io/hashicorp/aws
+type providerSource struct {
+	Host      string
+	Namespace string
+	Type      string
+	Aliases   []string
+}
+
+func (s providerSource) String() string {
+	return fmt.Sprintf("%s/%s/%s", s.Host, s.Namespace, s.Type)
+}
+
+// providerRegistry chooses, once per state, the source address and aliases of
+// each provider so that every resource using a provider agrees on them
+type providerRegistry struct {
+	options Options
+	version terraformVersion
+	sources map[string]providerSource
+}
+
+func newProviderRegistry(options Options, version terraformVersion) *providerRegistry {
+	return &providerRegistry{
+		options: options,
+		version: version,
+		sources: make(map[string]providerSource),
+	}
+}
+
+// source returns the source address for the named provider
+func (r *providerRegistry) source(providerName string) providerSource {
+	if source, ok := r.sources[providerName]; ok {
+		return source
+	}
+
+	source := providerSource{
+		Host:      r.options.RegistryHosts[rand.IntN(len(r.options.RegistryHosts))],
+		Namespace: r.options.ProviderNamespaces[rand.IntN(len(r.options.ProviderNamespaces))],
+		Type:      providerName,
+	}
+
+	// Each provider gets between one and three aliased configurations
+	for _, i := range rand.Perm(len(providerAliases))[:rand.IntN(3)+1] {
+		source.Aliases = append(source.Aliases, providerAliases[i])
+	}
+
+	r.sources[providerName] = source
+	return source
+}
+
+// configuration returns the address of the provider configuration a
+// resource uses, which is occasionally an aliased configuration. Terraform
+// 0.12 and earlier address providers by their type alone, as provider.aws.
+func (r *providerRegistry) configuration(resourceType string) (address string, aliased bool) {
+	source := r.source(getProviderFromResourceType(resourceType))
+
+	address = "provider." + source.Type
+	if r.version.supportsProviderSourceAddresses() {
+		address = fmt.Sprintf("provider[%q]", source)
+	}
+	if rand.IntN(100) < r.options.ProviderAliasChance {
+		return fmt.Sprintf("%s.%s", address, source.Aliases[rand.IntN(len(source.Aliases))]), true
+	}
+	return address, false
+}
+
+// providerAddress returns the address of a provider configuration as seen
+// from within a module
+func providerAddress(moduleAddress, configuration string) string {
+	if moduleAddress == "" {
+		return configuration
+	}
+	return fmt.Sprintf("%s.%s", moduleAddress, configuration)
+}
@@ -0,0 +1,100 @@
+package statefaker
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseProviderNamespaces(t *testing.T) {
+	namespaces, err := ParseProviderNamespaces(" hashicorp, acme,,")
+	if err != nil {
+		t.Fatalf("failed to parse namespaces: %v", err)
+	}
+	if !slices.Equal(namespaces, []string{"hashicorp", "acme"}) {
+		t.Errorf("expected trimmed namespaces without empty entries, got %q", namespaces)
+	}
+
+	for _, invalid := range []string{"", " , ", "acme corp", "-acme", "acme/aws"} {
+		if _, err := ParseProviderNamespaces(invalid); err == nil {
+			t.Errorf("expected an error parsing namespaces %q", invalid)
+		}
+	}
+}
+
+func TestParseRegistryHosts(t *testing.T) {
+	hosts, err := ParseRegistryHosts("registry.terraform.io, Registry.OpenTofu.org ,tf.example.com:8443")
+	if err != nil {
+		t.Fatalf("failed to parse registry hosts: %v", err)
+	}
+	if !slices.Equal(hosts, []string{"registry.terraform.io", "registry.opentofu.org", "tf.example.com:8443"}) {
+		t.Errorf("unexpected registry hosts %q", hosts)
+	}
+
+	for _, invalid := range []string{"", ",", "https://registry.terraform.io", "registry..io", "registry.terraform.io/"} {
+		if _, err := ParseRegistryHosts(invalid); err == nil {
+			t.Errorf("expected an error parsing registry hosts %q", invalid)
+		}
+	}
+}
+
+// providerConfiguration matches the address of a provider configuration
+var providerConfiguration = regexp.MustCompile(`^(module\.[^.]+\.)?provider\["([^/"]+)/([^/"]+)/([^/"]+)"\](\.(\w+))?$`)
+
+func TestProviderSources(t *testing.T) {
+	hosts := []string{"registry.terraform.io", "registry.opentofu.org"}
+	namespaces := []string{"hashicorp", "acme"}
+	state, err := NewFakeStateV4(
+		WithResources(200),
+		WithRegistryHosts(hosts...),
+		WithProviderNamespaces(" hashicorp", "", "acme "),
+		WithProviderAliasChance(50),
+	)
+	if err != nil {
+		t.Fatalf("failed to generate fake state: %v", err)
+	}
+
+	// Every resource using a provider agrees on its source
+	sources := make(map[string]string)
+	aliases := make(map[string]map[string]bool)
+	for _, resource := range state.Resources {
+		match := providerConfiguration.FindStringSubmatch(resource.Provider)
+		if match == nil {
+			t.Errorf("%s has an invalid provider address %s", resourceAddress(resource), resource.Provider)
+			continue
+		}
+		host, namespace, providerType, alias := match[2], match[3], match[4], match[6]
+		if !slices.Contains(hosts, host) || !slices.Contains(namespaces, namespace) {
+			t.Errorf("%s uses a provider from an unexpected host or namespace: %s", resourceAddress(resource), resource.Provider)
+		}
+		if !strings.HasPrefix(resource.Type, providerType+"_") {
+			t.Errorf("%s uses the %s provider", resourceAddress(resource), providerType)
+		}
+
+		source := host + "/" + namespace
+		if existing, ok := sources[providerType]; ok && existing != source {
+			t.Errorf("the %s provider comes from both %s and %s", providerType, existing, source)
+		}
+		sources[providerType] = source
+
+		if alias != "" {
+			if !slices.Contains(providerAliases, alias) {
+				t.Errorf("%s uses an unexpected alias %s", resourceAddress(resource), alias)
+			}
+			if aliases[providerType] == nil {
+				aliases[providerType] = make(map[string]bool)
+			}
+			aliases[providerType][alias] = true
+		}
+	}
+
+	if len(aliases) == 0 {
+		t.Error("expected some resources to use aliased provider configurations")
+	}
+	for providerType, names := range aliases {
+		if len(names) > 3 {
+			t.Errorf("expected at most three aliases of the %s provider, got %v", providerType, names)
+		}
+	}
+}
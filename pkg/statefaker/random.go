@@ -4,32 +4,24 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand/v2"
+	"strings"
 
 	"github.com/go-faker/faker/v4"
 )
 
-// Register custom faker providers for our specific fields. These are all used
-// by the InstanceV4 struct tags.
-func init() {
-	_ = faker.AddProvider("tfattributes", tfattributesProvider)
-	_ = faker.AddProvider("tfidentity", tfidentityProvider)
-	_ = faker.AddProvider("tfprivate", tfprivateProvider)
-	_ = faker.AddProvider("tfdependencies", tfdependenciesProvider)
-	_ = faker.AddProvider("tfemptystringslice", tfemptystringsliceProvider)
-}
-
 // Helper functions for generating realistic AWS data
 func generateAWSAccountID() string {
 	return fmt.Sprintf("%012d", rand.IntN(1000000000000))
 }
 
 func generateAWSRegion() string {
-	regions := []string{"us-east-1", "us-west-2", "eu-west-1", "ap-southeast-1", "ca-central-1"}
-	return regions[rand.IntN(len(regions))]
+	return awsRegions[rand.IntN(len(awsRegions))]
 }
 
-func generateARN(service, resource string) string {
-	return fmt.Sprintf("arn:aws:%s:%s:%s:%s", service, generateAWSRegion(), generateAWSAccountID(), resource)
+// generateAWSResourceID generates an EC2 style resource ID, such as
+// subnet-0123456789abcdef0
+func generateAWSResourceID(prefix string) string {
+	return fmt.Sprintf("%s-%s", prefix, faker.UUIDDigit()[:17])
 }
 
 func generateAccessKeyID() string {
@@ -62,66 +54,20 @@ func generateUserName() string {
 	return roles[rand.IntN(len(roles))]
 }
 
-func generateResourceType() string {
-	resourceTypes := []string{
-		"aws_s3_bucket", "aws_iam_user", "aws_iam_role", "aws_lambda_function",
-		"aws_ec2_instance", "aws_rds_instance", "aws_dynamodb_table", "aws_vpc",
-		"aws_security_group", "aws_route53_zone", "aws_cloudfront_distribution",
-		"aws_ecs_cluster", "aws_eks_cluster", "aws_api_gateway_rest_api",
-	}
-	return resourceTypes[rand.IntN(len(resourceTypes))]
-}
-
-func generateResourceName() string {
-	prefixes := []string{"app", "web", "api", "data", "ml", "core", "auth", "cache", "db", "svc"}
-	suffixes := []string{"prod", "staging", "dev", "test", "demo", "backup", "main", "primary", "secondary"}
-
-	// Use faker to generate a random word for the middle part
-	middlePart := faker.Word()
-
-	prefix := prefixes[rand.IntN(len(prefixes))]
-	suffix := suffixes[rand.IntN(len(suffixes))]
-
-	return fmt.Sprintf("%s-%s-%s-%d", prefix, middlePart, suffix, faker.UnixTime())
-}
-
-func generateModuleAddress() string {
-	modules := []string{
-		"hashicorp_cloud", "aws_infrastructure", "networking", "security",
-		"database", "monitoring", "backup", "analytics", "compute",
-		"storage", "identity", "logging", "encryption", "vpc_setup",
-	}
-	return "module." + modules[rand.IntN(len(modules))]
-}
-
-func generateProviderString(resourceType, moduleAddress string) string {
-	providerName := getProviderFromResourceType(resourceType)
-	if moduleAddress != "" {
-		return fmt.Sprintf("%s.provider[\"registry.terraform.io/hashicorp/%s\"]", moduleAddress, providerName)
-	}
-	return fmt.Sprintf("provider[\"registry.terraform.io/hashicorp/%s\"]", providerName)
-}
-
 func getProviderFromResourceType(resourceType string) string {
-	if len(resourceType) >= 3 && resourceType[:3] == "aws" {
-		return "aws"
-	}
-	if len(resourceType) >= 5 && resourceType[:5] == "azurerm" {
-		return "azurerm"
-	}
-	if len(resourceType) >= 6 && resourceType[:6] == "google" {
-		return "google"
-	}
-	if len(resourceType) >= 10 && resourceType[:10] == "kubernetes" {
-		return "kubernetes"
+	// Resource types are prefixed with the name of their provider
+	for _, provider := range []string{"aws", "azurerm", "google", "kubernetes", "helm"} {
+		if strings.HasPrefix(resourceType, provider+"_") {
+			return provider
+		}
 	}
 	// Default to aws for most cases
 	return "aws"
 }
 
 // generateComplexOutput generates complex realistic output structures
-func generateComplexOutput(output *OutputV4) {
-	outputTypes := []func(*OutputV4){
+func generateComplexOutput(output *OutputV4, location awsLocation, names *nameSequence) {
+	outputTypes := []func(*OutputV4, awsLocation, *nameSequence){
 		generateS3BucketPolicyOutput,
 		generateUserMapOutput,
 		generateDatabaseConfigOutput,
@@ -130,12 +76,11 @@ func generateComplexOutput(output *OutputV4) {
 	}
 
 	generator := outputTypes[rand.IntN(len(outputTypes))]
-	generator(output)
+	generator(output, location, names)
 }
 
-func generateS3BucketPolicyOutput(output *OutputV4) {
+func generateS3BucketPolicyOutput(output *OutputV4, location awsLocation, names *nameSequence) {
 	bucketName := generateS3BucketName()
-	accountID := generateAWSAccountID()
 	userName := generateUserName()
 
 	policy := map[string]any{
@@ -145,21 +90,21 @@ func generateS3BucketPolicyOutput(output *OutputV4) {
 				"Effect": "Allow",
 				"Action": "s3:ListBucket",
 				"Resource": []string{
-					generateARN("s3", bucketName+"/*"),
-					generateARN("s3", bucketName),
+					s3ARN(bucketName + "/*"),
+					s3ARN(bucketName),
 				},
 				"Principal": map[string]string{
-					"AWS": generateARN("iam", fmt.Sprintf("user/%s", userName)),
+					"AWS": location.globalARN("iam", fmt.Sprintf("user/%s", userName)),
 				},
 			},
 			{
 				"Effect": "Allow",
 				"Action": "s3:GetObject",
 				"Resource": []string{
-					generateARN("s3", bucketName+"/*"),
+					s3ARN(bucketName + "/*"),
 				},
 				"Principal": map[string]string{
-					"AWS": fmt.Sprintf("arn:aws:iam::%s:user/%s", accountID, userName),
+					"AWS": fmt.Sprintf("arn:aws:iam::%s:user/%s", location.AccountID, userName),
 				},
 			},
 		},
@@ -174,7 +119,7 @@ func generateS3BucketPolicyOutput(output *OutputV4) {
 	output.Value = json.RawMessage(valueJSON)
 }
 
-func generateUserMapOutput(output *OutputV4) {
+func generateUserMapOutput(output *OutputV4, location awsLocation, names *nameSequence) {
 	users := make(map[string]map[string]any)
 
 	for i := 0; i < rand.IntN(5)+2; i++ {
@@ -228,11 +173,14 @@ func generateUserMapOutput(output *OutputV4) {
 	valueJSON, _ := json.Marshal(users)
 	output.Type = json.RawMessage(typeJSON)
 	output.Value = json.RawMessage(valueJSON)
+	// Terraform refuses to output secret access keys unless the output is
+	// marked sensitive
+	output.Sensitive = true
 }
 
-func generateDatabaseConfigOutput(output *OutputV4) {
+func generateDatabaseConfigOutput(output *OutputV4, location awsLocation, names *nameSequence) {
 	config := map[string]any{
-		"endpoint":                fmt.Sprintf("%s.%s.rds.amazonaws.com", faker.Username(), generateAWSRegion()),
+		"endpoint":                fmt.Sprintf("%s.%s.rds.amazonaws.com", faker.Username(), location.Region),
 		"port":                    5432,
 		"database":                faker.Username(),
 		"username":                faker.Username(),
@@ -259,9 +207,12 @@ func generateDatabaseConfigOutput(output *OutputV4) {
 	valueJSON, _ := json.Marshal(config)
 	output.Type = json.RawMessage(typeJSON)
 	output.Value = json.RawMessage(valueJSON)
+	// The password comes from a sensitive attribute, so the output must be
+	// marked sensitive too
+	output.Sensitive = true
 }
 
-func generateNetworkConfigOutput(output *OutputV4) {
+func generateNetworkConfigOutput(output *OutputV4, location awsLocation, names *nameSequence) {
 	config := map[string]any{
 		"vpc_id": fmt.Sprintf("vpc-%s", faker.UUIDDigit()),
 		"subnet_ids": []string{
@@ -272,8 +223,8 @@ func generateNetworkConfigOutput(output *OutputV4) {
 			fmt.Sprintf("sg-%s", faker.UUIDDigit()),
 		},
 		"availability_zones": []string{
-			generateAWSRegion() + "a",
-			generateAWSRegion() + "b",
+			location.Region + "a",
+			location.Region + "b",
 		},
 		"cidr_block": "10.0.0.0/16",
 	}
@@ -294,7 +245,7 @@ func generateNetworkConfigOutput(output *OutputV4) {
 	output.Value = json.RawMessage(valueJSON)
 }
 
-func generateSecurityGroupOutput(output *OutputV4) {
+func generateSecurityGroupOutput(output *OutputV4, location awsLocation, names *nameSequence) {
 	rules := make([]map[string]any, rand.IntN(5)+1)
 	for i := range rules {
 		rules[i] = map[string]any{
@@ -308,7 +259,7 @@ func generateSecurityGroupOutput(output *OutputV4) {
 
 	config := map[string]any{
 		"id":          fmt.Sprintf("sg-%s", faker.UUIDDigit()),
-		"name":        fmt.Sprintf("%s-sg", generateResourceName()),
+		"name":        fmt.Sprintf("%s-sg", names.name()),
 		"description": faker.Sentence(),
 		"rules":       rules,
 		"vpc_id":      fmt.Sprintf("vpc-%s", faker.UUIDDigit()),
@@ -346,15 +297,30 @@ func generateSecurityGroupOutput(output *OutputV4) {
 }
 
 // Attribute generators for different resource types
-func generateS3BucketAttributes() map[string]any {
+func generateS3BucketAttributes(ctx *attributeContext) map[string]any {
+	encryption := map[string]any{
+		"sse_algorithm": "AES256",
+	}
+	// Some buckets are encrypted with a customer managed key
+	if rand.IntN(2) == 0 {
+		if keys := ctx.pick("aws_kms_key", 1, 1); len(keys) > 0 {
+			encryption = map[string]any{
+				"sse_algorithm":     "aws:kms",
+				"kms_master_key_id": keys[0]["arn"],
+			}
+		}
+	}
+
 	bucketName := generateS3BucketName()
+	region := ctx.location.Region
+	tags, tagsAll := ctx.tags(nil)
 	return map[string]any{
 		"id":                          bucketName,
-		"arn":                         generateARN("s3", bucketName),
+		"arn":                         s3ARN(bucketName),
 		"bucket":                      bucketName,
 		"bucket_domain_name":          fmt.Sprintf("%s.s3.amazonaws.com", bucketName),
-		"bucket_regional_domain_name": fmt.Sprintf("%s.s3.%s.amazonaws.com", bucketName, generateAWSRegion()),
-		"region":                      generateAWSRegion(),
+		"bucket_regional_domain_name": fmt.Sprintf("%s.s3.%s.amazonaws.com", bucketName, region),
+		"region":                      region,
 		"versioning": []map[string]any{
 			{
 				"enabled":    rand.IntN(2) == 1,
@@ -365,68 +331,79 @@ func generateS3BucketAttributes() map[string]any {
 			{
 				"rule": []map[string]any{
 					{
-						"apply_server_side_encryption_by_default": []map[string]any{
-							{
-								"sse_algorithm": "AES256",
-							},
-						},
+						"apply_server_side_encryption_by_default": []map[string]any{encryption},
 					},
 				},
 			},
 		},
-		"tags": map[string]string{
-			"Environment": []string{"prod", "staging", "dev"}[rand.IntN(3)],
-			"Team":        []string{"data", "ml", "web", "mobile"}[rand.IntN(4)],
-		},
+		"tags":     tags,
+		"tags_all": tagsAll,
 	}
 }
 
-func generateIAMUserAttributes() map[string]any {
+func generateIAMUserAttributes(ctx *attributeContext) map[string]any {
 	userName := generateUserName()
+	tags, tagsAll := ctx.tags(map[string]string{
+		"Role": []string{"reader", "writer", "admin"}[rand.IntN(3)],
+	})
 	return map[string]any{
 		"id":                   userName,
-		"arn":                  generateARN("iam", fmt.Sprintf("user/%s", userName)),
+		"arn":                  ctx.location.globalARN("iam", fmt.Sprintf("user/%s", userName)),
 		"name":                 userName,
 		"path":                 "/",
 		"permissions_boundary": nil,
 		"unique_id":            fmt.Sprintf("AIDA%s", faker.UUIDDigit()[:16]),
-		"tags": map[string]string{
-			"Role": []string{"reader", "writer", "admin"}[rand.IntN(3)],
-			"Team": []string{"data", "ml", "security"}[rand.IntN(3)],
-		},
+		"tags":                 tags,
+		"tags_all":             tagsAll,
 	}
 }
 
-func generateEC2InstanceAttributes() map[string]any {
+func generateEC2InstanceAttributes(ctx *attributeContext) map[string]any {
 	instanceID := fmt.Sprintf("i-%s", faker.UUIDDigit()[:17])
+	region := ctx.location.Region
+	subnetID := generateAWSResourceID("subnet")
+	availabilityZone := ctx.location.availabilityZone()
+	// Instances are launched into an existing subnet, in its availability zone
+	if subnets := ctx.pick("aws_subnet", 1, 1); len(subnets) > 0 {
+		subnetID = subnets[0]["id"].(string)
+		availabilityZone = subnets[0]["availability_zone"].(string)
+	}
+	tags, tagsAll := ctx.tags(map[string]string{
+		"Name": fmt.Sprintf("%s-instance", ctx.name()),
+	})
 	return map[string]any{
 		"id":                     instanceID,
-		"arn":                    generateARN("ec2", fmt.Sprintf("instance/%s", instanceID)),
+		"arn":                    ctx.location.arn("ec2", fmt.Sprintf("instance/%s", instanceID)),
+		"region":                 region,
 		"instance_id":            instanceID,
 		"instance_type":          []string{"t3.micro", "t3.small", "m5.large", "c5.xlarge"}[rand.IntN(4)],
 		"ami":                    fmt.Sprintf("ami-%s", faker.UUIDDigit()[:17]),
-		"availability_zone":      generateAWSRegion() + []string{"a", "b", "c"}[rand.IntN(3)],
+		"availability_zone":      availabilityZone,
 		"private_ip":             fmt.Sprintf("10.0.%d.%d", rand.IntN(255), rand.IntN(255)),
 		"public_ip":              fmt.Sprintf("%d.%d.%d.%d", rand.IntN(255), rand.IntN(255), rand.IntN(255), rand.IntN(255)),
-		"subnet_id":              fmt.Sprintf("subnet-%s", faker.UUIDDigit()[:17]),
-		"vpc_security_group_ids": []string{fmt.Sprintf("sg-%s", faker.UUIDDigit()[:17])},
+		"subnet_id":              subnetID,
+		"vpc_security_group_ids": ctx.refs("aws_security_group", "id", 1, 2, func() string { return generateAWSResourceID("sg") }),
 		"key_name":               faker.Username(),
 		"monitoring":             rand.IntN(2) == 1,
 		"state":                  "running",
-		"tags": map[string]string{
-			"Name":        fmt.Sprintf("%s-instance", generateResourceName()),
-			"Environment": []string{"prod", "staging", "dev"}[rand.IntN(3)],
-		},
+		"tags":                   tags,
+		"tags_all":               tagsAll,
 	}
 }
 
-func generateLambdaFunctionAttributes() map[string]any {
-	functionName := fmt.Sprintf("%s-lambda", generateResourceName())
+func generateLambdaFunctionAttributes(ctx *attributeContext) map[string]any {
+	functionName := fmt.Sprintf("%s-lambda", ctx.name())
+	region := ctx.location.Region
+	role := ctx.ref("aws_iam_role", "arn", func() string {
+		return ctx.location.globalARN("iam", fmt.Sprintf("role/%s-lambda-role", ctx.name()))
+	})
+	tags, tagsAll := ctx.tags(nil)
 	return map[string]any{
 		"id":               functionName,
-		"arn":              generateARN("lambda", fmt.Sprintf("function:%s", functionName)),
+		"arn":              ctx.location.arn("lambda", fmt.Sprintf("function:%s", functionName)),
+		"region":           region,
 		"function_name":    functionName,
-		"role":             generateARN("iam", fmt.Sprintf("role/%s-lambda-role", generateResourceName())),
+		"role":             role,
 		"handler":          "index.handler",
 		"runtime":          []string{"nodejs18.x", "python3.9", "java11", "go1.x"}[rand.IntN(4)],
 		"memory_size":      []int{128, 256, 512, 1024}[rand.IntN(4)],
@@ -442,18 +419,26 @@ func generateLambdaFunctionAttributes() map[string]any {
 				},
 			},
 		},
-		"tags": map[string]string{
-			"Environment": []string{"prod", "staging", "dev"}[rand.IntN(3)],
-			"Team":        []string{"backend", "data", "ml"}[rand.IntN(3)],
-		},
+		"tags":     tags,
+		"tags_all": tagsAll,
 	}
 }
 
-func generateRDSInstanceAttributes() map[string]any {
-	instanceID := fmt.Sprintf("%s-db", generateResourceName())
+func generateRDSInstanceAttributes(ctx *attributeContext) map[string]any {
+	instanceID := fmt.Sprintf("%s-db", ctx.name())
+	region := ctx.location.Region
+	storageEncrypted := rand.IntN(2) == 1
+	kmsKeyID := ""
+	if storageEncrypted {
+		kmsKeyID = ctx.ref("aws_kms_key", "arn", func() string {
+			return ctx.location.arn("kms", fmt.Sprintf("key/%s", faker.UUIDHyphenated()))
+		})
+	}
+	tags, tagsAll := ctx.tags(nil)
 	return map[string]any{
 		"id":                      instanceID,
-		"arn":                     generateARN("rds", fmt.Sprintf("db:%s", instanceID)),
+		"arn":                     ctx.location.arn("rds", fmt.Sprintf("db:%s", instanceID)),
+		"region":                  region,
 		"identifier":              instanceID,
 		"engine":                  []string{"postgres", "mysql", "mariadb"}[rand.IntN(3)],
 		"engine_version":          []string{"13.7", "14.2", "8.0.28"}[rand.IntN(3)],
@@ -462,23 +447,362 @@ func generateRDSInstanceAttributes() map[string]any {
 		"storage_type":            "gp2",
 		"db_name":                 faker.Username(),
 		"username":                faker.Username(),
+		"password":                faker.Password(),
 		"port":                    []int{3306, 5432}[rand.IntN(2)],
-		"endpoint":                fmt.Sprintf("%s.%s.%s.rds.amazonaws.com", instanceID, faker.UUIDDigit()[:10], generateAWSRegion()),
+		"endpoint":                fmt.Sprintf("%s.%s.%s.rds.amazonaws.com", instanceID, faker.UUIDDigit()[:10], region),
 		"hosted_zone_id":          fmt.Sprintf("Z%s", faker.UUIDDigit()[:13]),
 		"status":                  "available",
 		"multi_az":                rand.IntN(2) == 1,
 		"backup_retention_period": rand.IntN(35) + 1,
 		"backup_window":           "03:00-04:00",
 		"maintenance_window":      "sun:04:00-sun:05:00",
-		"storage_encrypted":       rand.IntN(2) == 1,
-		"tags": map[string]string{
-			"Environment": []string{"prod", "staging", "dev"}[rand.IntN(3)],
-			"Team":        []string{"data", "backend", "analytics"}[rand.IntN(3)],
+		"storage_encrypted":       storageEncrypted,
+		"kms_key_id":              kmsKeyID,
+		"vpc_security_group_ids":  ctx.refs("aws_security_group", "id", 1, 2, func() string { return generateAWSResourceID("sg") }),
+		"tags":                    tags,
+		"tags_all":                tagsAll,
+	}
+}
+
+func generateIAMRoleAttributes(ctx *attributeContext) map[string]any {
+	roleName := fmt.Sprintf("%s-role", ctx.name())
+	tags, tagsAll := ctx.tags(nil)
+	return map[string]any{
+		"id":                    roleName,
+		"arn":                   ctx.location.globalARN("iam", fmt.Sprintf("role/%s", roleName)),
+		"name":                  roleName,
+		"path":                  "/",
+		"assume_role_policy":    generateAssumeRolePolicy(ctx.location),
+		"max_session_duration":  3600,
+		"force_detach_policies": false,
+		"unique_id":             fmt.Sprintf("AROA%s", faker.UUIDDigit()[:16]),
+		"create_date":           faker.Timestamp(),
+		"tags":                  tags,
+		"tags_all":              tagsAll,
+	}
+}
+
+func generateIAMPolicyAttributes(ctx *attributeContext) map[string]any {
+	policyName := fmt.Sprintf("%s-policy", ctx.name())
+	arn := ctx.location.globalARN("iam", fmt.Sprintf("policy/%s", policyName))
+	tags, tagsAll := ctx.tags(nil)
+	return map[string]any{
+		"id":               arn,
+		"arn":              arn,
+		"name":             policyName,
+		"name_prefix":      "",
+		"path":             "/",
+		"description":      faker.Sentence(),
+		"policy":           generatePolicyDocument(ctx, 1, 8),
+		"policy_id":        fmt.Sprintf("ANPA%s", strings.ToUpper(faker.UUIDDigit()[:17])),
+		"attachment_count": rand.IntN(4),
+		"tags":             tags,
+		"tags_all":         tagsAll,
+	}
+}
+
+func generateS3BucketPolicyAttributes(ctx *attributeContext) map[string]any {
+	bucketName := generateS3BucketName()
+	// Bucket policies are attached to an existing bucket
+	if buckets := ctx.pick("aws_s3_bucket", 1, 1); len(buckets) > 0 {
+		bucketName = buckets[0]["bucket"].(string)
+	}
+	return map[string]any{
+		"id":     bucketName,
+		"bucket": bucketName,
+		"policy": generateBucketPolicy(ctx, s3ARN(bucketName)),
+	}
+}
+
+func generateECSTaskDefinitionAttributes(ctx *attributeContext) map[string]any {
+	family := fmt.Sprintf("%s-task", ctx.name())
+	revision := rand.IntN(120) + 1
+	arnWithoutRevision := ctx.location.arn("ecs", fmt.Sprintf("task-definition/%s", family))
+	roleARN := func() string {
+		return ctx.location.globalARN("iam", fmt.Sprintf("role/%s-role", family))
+	}
+	cpu, memory := []string{"256", "512", "1024", "2048"}[rand.IntN(4)], []string{"512", "1024", "2048", "4096"}[rand.IntN(4)]
+	tags, tagsAll := ctx.tags(nil)
+	return map[string]any{
+		"id":                       family,
+		"arn":                      fmt.Sprintf("%s:%d", arnWithoutRevision, revision),
+		"arn_without_revision":     arnWithoutRevision,
+		"family":                   family,
+		"revision":                 revision,
+		"region":                   ctx.location.Region,
+		"container_definitions":    generateContainerDefinitions(ctx, family),
+		"cpu":                      cpu,
+		"memory":                   memory,
+		"network_mode":             "awsvpc",
+		"requires_compatibilities": []string{"FARGATE"},
+		"execution_role_arn":       ctx.ref("aws_iam_role", "arn", roleARN),
+		"task_role_arn":            ctx.ref("aws_iam_role", "arn", roleARN),
+		"skip_destroy":             false,
+		"track_latest":             false,
+		"tags":                     tags,
+		"tags_all":                 tagsAll,
+	}
+}
+
+func generateDynamoDBTableAttributes(ctx *attributeContext) map[string]any {
+	tableName := fmt.Sprintf("%s-table", ctx.name())
+	region := ctx.location.Region
+	tags, tagsAll := ctx.tags(nil)
+	return map[string]any{
+		"id":             tableName,
+		"arn":            ctx.location.arn("dynamodb", fmt.Sprintf("table/%s", tableName)),
+		"name":           tableName,
+		"region":         region,
+		"billing_mode":   []string{"PAY_PER_REQUEST", "PROVISIONED"}[rand.IntN(2)],
+		"hash_key":       []string{"id", "pk", "tenant_id"}[rand.IntN(3)],
+		"range_key":      []string{"", "sk", "created_at"}[rand.IntN(3)],
+		"read_capacity":  []int{0, 5, 20}[rand.IntN(3)],
+		"write_capacity": []int{0, 5, 20}[rand.IntN(3)],
+		"stream_enabled": rand.IntN(2) == 1,
+		"attribute": []map[string]any{
+			{
+				"name": "id",
+				"type": "S",
+			},
+		},
+		"point_in_time_recovery": []map[string]any{
+			{
+				"enabled": rand.IntN(2) == 1,
+			},
+		},
+		"tags":     tags,
+		"tags_all": tagsAll,
+	}
+}
+
+func generateVPCAttributes(ctx *attributeContext) map[string]any {
+	vpcID := fmt.Sprintf("vpc-%s", faker.UUIDDigit()[:17])
+	region := ctx.location.Region
+	tags, tagsAll := ctx.tags(map[string]string{
+		"Name": fmt.Sprintf("%s-vpc", ctx.name()),
+	})
+	return map[string]any{
+		"id":                     vpcID,
+		"arn":                    ctx.location.arn("ec2", fmt.Sprintf("vpc/%s", vpcID)),
+		"region":                 region,
+		"cidr_block":             fmt.Sprintf("10.%d.0.0/16", rand.IntN(256)),
+		"instance_tenancy":       "default",
+		"enable_dns_hostnames":   rand.IntN(2) == 1,
+		"enable_dns_support":     true,
+		"main_route_table_id":    fmt.Sprintf("rtb-%s", faker.UUIDDigit()[:17]),
+		"default_network_acl_id": fmt.Sprintf("acl-%s", faker.UUIDDigit()[:17]),
+		"tags":                   tags,
+		"tags_all":               tagsAll,
+	}
+}
+
+func generateSubnetAttributes(ctx *attributeContext) map[string]any {
+	subnetID := generateAWSResourceID("subnet")
+	region := ctx.location.Region
+	vpcID := generateAWSResourceID("vpc")
+	cidrBlock := fmt.Sprintf("10.%d.%d.0/24", rand.IntN(256), rand.IntN(256))
+	// Subnets are carved out of an existing VPC's address space
+	if vpcs := ctx.pick("aws_vpc", 1, 1); len(vpcs) > 0 {
+		vpcID = vpcs[0]["id"].(string)
+		cidrBlock = strings.Replace(vpcs[0]["cidr_block"].(string), ".0.0/16", fmt.Sprintf(".%d.0/24", rand.IntN(256)), 1)
+	}
+	tags, tagsAll := ctx.tags(map[string]string{
+		"Name": fmt.Sprintf("%s-subnet", ctx.name()),
+		"Tier": []string{"public", "private", "database"}[rand.IntN(3)],
+	})
+	return map[string]any{
+		"id":                      subnetID,
+		"arn":                     ctx.location.arn("ec2", fmt.Sprintf("subnet/%s", subnetID)),
+		"region":                  region,
+		"vpc_id":                  vpcID,
+		"cidr_block":              cidrBlock,
+		"availability_zone":       ctx.location.availabilityZone(),
+		"map_public_ip_on_launch": rand.IntN(2) == 1,
+		"tags":                    tags,
+		"tags_all":                tagsAll,
+	}
+}
+
+func generateKMSKeyAttributes(ctx *attributeContext) map[string]any {
+	keyID := faker.UUIDHyphenated()
+	region := ctx.location.Region
+	tags, tagsAll := ctx.tags(nil)
+	return map[string]any{
+		"id":                       keyID,
+		"key_id":                   keyID,
+		"arn":                      ctx.location.arn("kms", fmt.Sprintf("key/%s", keyID)),
+		"region":                   region,
+		"description":              faker.Sentence(),
+		"key_usage":                "ENCRYPT_DECRYPT",
+		"customer_master_key_spec": "SYMMETRIC_DEFAULT",
+		"enable_key_rotation":      rand.IntN(2) == 1,
+		"deletion_window_in_days":  []int{7, 10, 30}[rand.IntN(3)],
+		"is_enabled":               true,
+		"multi_region":             false,
+		"policy":                   encodePolicyDocument(defaultKeyPolicy(ctx.location)),
+		"tags":                     tags,
+		"tags_all":                 tagsAll,
+	}
+}
+
+func generateSecurityGroupAttributes(ctx *attributeContext) map[string]any {
+	groupID := fmt.Sprintf("sg-%s", faker.UUIDDigit()[:17])
+	region := ctx.location.Region
+	ingress := make([]map[string]any, rand.IntN(4)+1)
+	for i := range ingress {
+		port := []int{22, 80, 443, 5432, 6379, 8080}[rand.IntN(6)]
+		ingress[i] = map[string]any{
+			"description": faker.Sentence(),
+			"protocol":    "tcp",
+			"from_port":   port,
+			"to_port":     port,
+			"cidr_blocks": []string{fmt.Sprintf("10.%d.0.0/16", rand.IntN(256))},
+			"self":        false,
+		}
+	}
+	tags, tagsAll := ctx.tags(nil)
+	return map[string]any{
+		"id":          groupID,
+		"arn":         ctx.location.arn("ec2", fmt.Sprintf("security-group/%s", groupID)),
+		"region":      region,
+		"name":        fmt.Sprintf("%s-sg", ctx.name()),
+		"description": "Managed by Terraform",
+		"vpc_id":      ctx.ref("aws_vpc", "id", func() string { return generateAWSResourceID("vpc") }),
+		"ingress":     ingress,
+		"egress": []map[string]any{
+			{
+				"protocol":    "-1",
+				"from_port":   0,
+				"to_port":     0,
+				"cidr_blocks": []string{"0.0.0.0/0"},
+				"self":        false,
+			},
+		},
+		"tags":     tags,
+		"tags_all": tagsAll,
+	}
+}
+
+func generateRoute53ZoneAttributes(ctx *attributeContext) map[string]any {
+	zoneID := fmt.Sprintf("Z%s", strings.ToUpper(faker.UUIDDigit()[:13]))
+	zoneName := faker.DomainName()
+	tags, tagsAll := ctx.tags(nil)
+	return map[string]any{
+		"id":      zoneID,
+		"arn":     fmt.Sprintf("arn:aws:route53:::hostedzone/%s", zoneID),
+		"zone_id": zoneID,
+		"name":    zoneName,
+		"comment": "Managed by Terraform",
+		"name_servers": []string{
+			fmt.Sprintf("ns-%d.awsdns-%02d.com", rand.IntN(2048), rand.IntN(64)),
+			fmt.Sprintf("ns-%d.awsdns-%02d.net", rand.IntN(2048), rand.IntN(64)),
+			fmt.Sprintf("ns-%d.awsdns-%02d.org", rand.IntN(2048), rand.IntN(64)),
+			fmt.Sprintf("ns-%d.awsdns-%02d.co.uk", rand.IntN(2048), rand.IntN(64)),
+		},
+		"force_destroy": false,
+		"tags":          tags,
+		"tags_all":      tagsAll,
+	}
+}
+
+func generateCloudFrontDistributionAttributes(ctx *attributeContext) map[string]any {
+	distributionID := fmt.Sprintf("E%s", strings.ToUpper(faker.UUIDDigit()[:13]))
+	tags, tagsAll := ctx.tags(nil)
+	return map[string]any{
+		"id":                  distributionID,
+		"arn":                 ctx.location.globalARN("cloudfront", fmt.Sprintf("distribution/%s", distributionID)),
+		"domain_name":         fmt.Sprintf("d%s.cloudfront.net", faker.UUIDDigit()[:13]),
+		"enabled":             true,
+		"is_ipv6_enabled":     rand.IntN(2) == 1,
+		"price_class":         []string{"PriceClass_All", "PriceClass_100", "PriceClass_200"}[rand.IntN(3)],
+		"status":              "Deployed",
+		"hosted_zone_id":      "Z2FDTNDATAQYW2",
+		"default_root_object": "index.html",
+		"origin": []map[string]any{
+			{
+				"origin_id":   fmt.Sprintf("S3-%s", generateS3BucketName()),
+				"domain_name": fmt.Sprintf("%s.s3.amazonaws.com", generateS3BucketName()),
+			},
+		},
+		"tags":     tags,
+		"tags_all": tagsAll,
+	}
+}
+
+func generateECSClusterAttributes(ctx *attributeContext) map[string]any {
+	clusterName := fmt.Sprintf("%s-cluster", ctx.name())
+	region := ctx.location.Region
+	arn := ctx.location.arn("ecs", fmt.Sprintf("cluster/%s", clusterName))
+	tags, tagsAll := ctx.tags(nil)
+	return map[string]any{
+		"id":     arn,
+		"arn":    arn,
+		"name":   clusterName,
+		"region": region,
+		"setting": []map[string]any{
+			{
+				"name":  "containerInsights",
+				"value": []string{"enabled", "disabled"}[rand.IntN(2)],
+			},
+		},
+		"tags":     tags,
+		"tags_all": tagsAll,
+	}
+}
+
+func generateEKSClusterAttributes(ctx *attributeContext) map[string]any {
+	clusterName := fmt.Sprintf("%s-eks", ctx.name())
+	region := ctx.location.Region
+	roleARN := ctx.ref("aws_iam_role", "arn", func() string {
+		return ctx.location.globalARN("iam", fmt.Sprintf("role/%s-eks-role", clusterName))
+	})
+	tags, tagsAll := ctx.tags(nil)
+	return map[string]any{
+		"id":       clusterName,
+		"arn":      ctx.location.arn("eks", fmt.Sprintf("cluster/%s", clusterName)),
+		"name":     clusterName,
+		"region":   region,
+		"version":  []string{"1.29", "1.30", "1.31"}[rand.IntN(3)],
+		"endpoint": fmt.Sprintf("https://%s.gr7.%s.eks.amazonaws.com", strings.ToUpper(faker.UUIDDigit()), region),
+		"role_arn": roleARN,
+		"status":   "ACTIVE",
+		"vpc_config": []map[string]any{
+			{
+				"endpoint_private_access": true,
+				"endpoint_public_access":  rand.IntN(2) == 1,
+				"vpc_id":                  ctx.ref("aws_vpc", "id", func() string { return generateAWSResourceID("vpc") }),
+				"subnet_ids":              ctx.refs("aws_subnet", "id", 2, 3, func() string { return generateAWSResourceID("subnet") }),
+				"security_group_ids":      ctx.refs("aws_security_group", "id", 1, 1, func() string { return generateAWSResourceID("sg") }),
+			},
+		},
+		"tags":     tags,
+		"tags_all": tagsAll,
+	}
+}
+
+func generateAPIGatewayRestAPIAttributes(ctx *attributeContext) map[string]any {
+	apiID := faker.UUIDDigit()[:10]
+	region := ctx.location.Region
+	tags, tagsAll := ctx.tags(nil)
+	return map[string]any{
+		"id":               apiID,
+		"arn":              fmt.Sprintf("arn:aws:apigateway:%s::/restapis/%s", region, apiID),
+		"name":             fmt.Sprintf("%s-api", ctx.name()),
+		"region":           region,
+		"description":      faker.Sentence(),
+		"root_resource_id": faker.UUIDDigit()[:10],
+		"execution_arn":    ctx.location.arn("execute-api", apiID),
+		"endpoint_configuration": []map[string]any{
+			{
+				"types": []string{[]string{"REGIONAL", "EDGE", "PRIVATE"}[rand.IntN(3)]},
+			},
 		},
+		"tags":     tags,
+		"tags_all": tagsAll,
 	}
 }
 
-func generateOutput() (json.RawMessage, error) {
+func generateOutput(location awsLocation, names *nameSequence) (json.RawMessage, error) {
 	var output OutputV4
 
 	// Half the time, generate a simple output
@@ -503,7 +827,7 @@ func generateOutput() (json.RawMessage, error) {
 		output.Value = json.RawMessage(jsonValue)
 	} else {
 		// Half the time, generate a complex output
-		generateComplexOutput(&output)
+		generateComplexOutput(&output, location, names)
 	}
 
 	b, err := json.Marshal(output)
@@ -0,0 +1,120 @@
+package statefaker
+
+import (
+	"math/rand/v2"
+	"slices"
+)
+
+// reference is a generated resource instance that later resources can refer to
+type reference struct {
+	// address is the address of the resource, as recorded in the
+	// dependencies of instances that refer to it
+	address    string
+	managed    bool
+	location   awsLocation
+	attributes map[string]any
+}
+
+// referencePool holds the resource instances generated so far in a state, by
+// resource type, so that dependent resources can refer to real IDs and ARNs
+type referencePool struct {
+	byType map[string][]reference
+}
+
+func newReferencePool() *referencePool {
+	return &referencePool{byType: make(map[string][]reference)}
+}
+
+func (p *referencePool) add(resourceType string, ref reference) {
+	p.byType[resourceType] = append(p.byType[resourceType], ref)
+}
+
+// attributeContext is passed to attribute generators. It gives them the
+// account and region of the resource's provider configuration, the state's
+// Azure subscription and Google Cloud project, and the resources generated
+// before them to refer to, and records the resources they referred to.
+type attributeContext struct {
+	location     awsLocation
+	azure        azureLocation
+	google       googleLocation
+	pool         *referencePool
+	tagger       *tagger
+	names        *nameSequence
+	dependencies []string
+}
+
+// newAttributeContext returns a context for a resource in location, or in
+// the Azure subscription or Google Cloud project of locations, drawing
+// references from pool, which may be nil when generated attributes should
+// not refer to anything, tags from tagger and cloud-side names from names
+func newAttributeContext(locations *locationRegistry, location awsLocation, pool *referencePool, tagger *tagger, names *nameSequence) *attributeContext {
+	return &attributeContext{
+		location: location,
+		azure:    locations.azure,
+		google:   locations.google,
+		pool:     pool,
+		tagger:   tagger,
+		names:    names,
+	}
+}
+
+// name returns the next cloud-side name, like the name of a bucket or role
+func (c *attributeContext) name() string {
+	return c.names.name()
+}
+
+// tags returns the tags and tags_all of a taggable resource, including any
+// resource specific tags given
+func (c *attributeContext) tags(specific map[string]string) (tags, tagsAll map[string]string) {
+	return c.tagger.tags(specific)
+}
+
+// pick returns the attributes of between low and high distinct existing
+// resource instances of the given type in the same account and region,
+// recording the resources they belong to as dependencies. It returns nil
+// when there are none.
+func (c *attributeContext) pick(resourceType string, low, high int) []map[string]any {
+	if c.pool == nil {
+		return nil
+	}
+	var candidates []reference
+	for _, candidate := range c.pool.byType[resourceType] {
+		if candidate.location == c.location {
+			candidates = append(candidates, candidate)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var picked []map[string]any
+	for _, i := range rand.Perm(len(candidates))[:min(len(candidates), rand.IntN(high-low+1)+low)] {
+		candidate := candidates[i]
+		if candidate.managed && !slices.Contains(c.dependencies, candidate.address) {
+			c.dependencies = append(c.dependencies, candidate.address)
+		}
+		picked = append(picked, candidate.attributes)
+	}
+	return picked
+}
+
+// ref returns a string attribute of an existing resource of the given type,
+// or the result of fallback when there is none
+func (c *attributeContext) ref(resourceType, attribute string, fallback func() string) string {
+	return c.refs(resourceType, attribute, 1, 1, fallback)[0]
+}
+
+// refs returns a string attribute of between low and high existing resources
+// of the given type, or the single result of fallback when there are none
+func (c *attributeContext) refs(resourceType, attribute string, low, high int, fallback func() string) []string {
+	var values []string
+	for _, attributes := range c.pick(resourceType, low, high) {
+		if value, ok := attributes[attribute].(string); ok {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return []string{fallback()}
+	}
+	return values
+}
@@ -0,0 +1,85 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestReferences(t *testing.T) {
+	state, err := NewFakeStateV4(WithResources(200))
+	if err != nil {
+		t.Fatalf("failed to generate fake state: %v", err)
+	}
+
+	addresses := make(map[string]bool)
+	ids := make(map[string][]string)
+	managed := make(map[string]bool)
+	for _, resource := range state.Resources {
+		addresses[resourceAddress(resource)] = true
+		for _, instance := range resource.Instances {
+			var attributes map[string]any
+			if err := json.Unmarshal(instance.Attributes, &attributes); err != nil {
+				t.Fatalf("failed to decode attributes: %v", err)
+			}
+			// Some resources, such as bucket policies, have no ARN
+			id, _ := attributes["id"].(string)
+			arn, _ := attributes["arn"].(string)
+			ids[resource.Type] = append(ids[resource.Type], id, arn)
+			if resource.Mode == "managed" {
+				managed[id] = true
+				managed[arn] = true
+			}
+		}
+	}
+
+	references := 0
+	for _, resource := range state.Resources {
+		for _, instance := range resource.Instances {
+			for _, dependency := range instance.Dependencies {
+				if !addresses[dependency] {
+					t.Errorf("%s depends on %s, which is not in the state", resourceAddress(resource), dependency)
+				}
+			}
+
+			var attributes struct {
+				SubnetID         string   `json:"subnet_id"`
+				SecurityGroupIDs []string `json:"vpc_security_group_ids"`
+				VPCID            string   `json:"vpc_id"`
+				Role             string   `json:"role"`
+			}
+			if err := json.Unmarshal(instance.Attributes, &attributes); err != nil {
+				t.Fatalf("failed to decode attributes: %v", err)
+			}
+
+			check := func(resourceType, value string) {
+				if value == "" || len(ids[resourceType]) == 0 {
+					return
+				}
+				references++
+				if !slices.Contains(ids[resourceType], value) {
+					t.Errorf("%s refers to %s %s, which is not in the state", resourceAddress(resource), resourceType, value)
+				}
+				// Only references to managed resources are dependencies
+				if managed[value] && len(instance.Dependencies) == 0 {
+					t.Errorf("%s refers to a %s but has no dependencies", resourceAddress(resource), resourceType)
+				}
+			}
+			switch resource.Type {
+			case "aws_instance":
+				check("aws_subnet", attributes.SubnetID)
+				for _, id := range attributes.SecurityGroupIDs {
+					check("aws_security_group", id)
+				}
+			case "aws_subnet", "aws_security_group":
+				check("aws_vpc", attributes.VPCID)
+			case "aws_lambda_function":
+				check("aws_iam_role", attributes.Role)
+			}
+		}
+	}
+
+	if references == 0 {
+		t.Error("expected some resources to refer to others")
+	}
+}
@@ -0,0 +1,131 @@
+package statefaker
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+// resourceType describes a resource type that statefaker knows how to generate
+type resourceType struct {
+	Name          string
+	SchemaVersion int
+	Attributes    func(ctx *attributeContext) map[string]any
+	// Tier orders generation so that resources are generated after the
+	// lower tier resources they can refer to, such as subnets after VPCs and
+	// policies after the resources they grant access to
+	Tier int
+	// Identity derives the resource identity from the generated attributes and
+	// the location they were generated in. It is nil for resource types that
+	// do not support resource identity.
+	Identity              func(location awsLocation, attributes map[string]any) map[string]any
+	IdentitySchemaVersion int
+}
+
+// resourceTypes is the catalog of resource types used when generating state
+var resourceTypes = []resourceType{
+	{Name: "aws_s3_bucket", Tier: 2, Attributes: generateS3BucketAttributes, Identity: awsIdentity("bucket")},
+	{Name: "aws_iam_user", Tier: 2, Attributes: generateIAMUserAttributes, Identity: awsIdentity("name")},
+	{Name: "aws_iam_role", Attributes: generateIAMRoleAttributes, Identity: awsIdentity("name")},
+	{Name: "aws_iam_policy", Tier: 3, Attributes: generateIAMPolicyAttributes, Identity: awsARNIdentity},
+	{Name: "aws_s3_bucket_policy", Tier: 3, Attributes: generateS3BucketPolicyAttributes},
+	{Name: "aws_kms_key", Attributes: generateKMSKeyAttributes, Identity: awsIdentity("id")},
+	{Name: "aws_lambda_function", Tier: 2, Attributes: generateLambdaFunctionAttributes, Identity: awsIdentity("function_name")},
+	{Name: "aws_instance", SchemaVersion: 1, Tier: 2, Attributes: generateEC2InstanceAttributes, Identity: awsIdentity("id")},
+	{Name: "aws_db_instance", SchemaVersion: 2, Tier: 2, Attributes: generateRDSInstanceAttributes, Identity: awsIdentity("identifier")},
+	{Name: "aws_dynamodb_table", SchemaVersion: 1, Tier: 2, Attributes: generateDynamoDBTableAttributes, Identity: awsIdentity("name")},
+	{Name: "aws_vpc", SchemaVersion: 1, Attributes: generateVPCAttributes, Identity: awsIdentity("id")},
+	{Name: "aws_subnet", SchemaVersion: 1, Tier: 1, Attributes: generateSubnetAttributes, Identity: awsIdentity("id")},
+	{Name: "aws_security_group", SchemaVersion: 1, Tier: 1, Attributes: generateSecurityGroupAttributes, Identity: awsIdentity("id")},
+	{Name: "aws_route53_zone", Tier: 2, Attributes: generateRoute53ZoneAttributes, Identity: awsIdentity("zone_id")},
+	{Name: "aws_cloudfront_distribution", SchemaVersion: 1, Tier: 2, Attributes: generateCloudFrontDistributionAttributes, Identity: awsIdentity("id")},
+	{Name: "aws_ecs_cluster", Tier: 2, Attributes: generateECSClusterAttributes, Identity: awsARNIdentity},
+	{Name: "aws_ecs_task_definition", SchemaVersion: 1, Tier: 2, Attributes: generateECSTaskDefinitionAttributes, Identity: awsARNIdentity},
+	{Name: "aws_eks_cluster", Tier: 2, Attributes: generateEKSClusterAttributes, Identity: awsIdentity("name")},
+	{Name: "aws_api_gateway_rest_api", Tier: 2, Attributes: generateAPIGatewayRestAPIAttributes, Identity: awsIdentity("id")},
+	{Name: "azurerm_resource_group", Attributes: generateAzureResourceGroupAttributes},
+	{Name: "azurerm_virtual_network", SchemaVersion: 1, Tier: 1, Attributes: generateAzureVirtualNetworkAttributes},
+	{Name: "azurerm_subnet", Tier: 2, Attributes: generateAzureSubnetAttributes},
+	{Name: "azurerm_linux_virtual_machine", Tier: 3, Attributes: generateAzureLinuxVirtualMachineAttributes},
+	{Name: "azurerm_storage_account", SchemaVersion: 4, Tier: 1, Attributes: generateAzureStorageAccountAttributes},
+	{Name: "azurerm_key_vault", SchemaVersion: 2, Tier: 1, Attributes: generateAzureKeyVaultAttributes},
+	{Name: "google_compute_network", Attributes: generateGoogleComputeNetworkAttributes},
+	{Name: "google_compute_instance", SchemaVersion: 6, Tier: 1, Attributes: generateGoogleComputeInstanceAttributes},
+	{Name: "google_storage_bucket", SchemaVersion: 3, Tier: 1, Attributes: generateGoogleStorageBucketAttributes},
+	{Name: "google_project_iam_member", Tier: 2, Attributes: generateGoogleProjectIAMMemberAttributes},
+	{Name: "google_container_cluster", SchemaVersion: 2, Tier: 1, Attributes: generateGoogleContainerClusterAttributes},
+	{Name: "google_sql_database_instance", SchemaVersion: 1, Tier: 1, Attributes: generateGoogleSQLDatabaseInstanceAttributes},
+	{Name: "kubernetes_config_map", Tier: 1, Attributes: generateKubernetesConfigMapAttributes},
+	{Name: "kubernetes_deployment", SchemaVersion: 1, Tier: 2, Attributes: generateKubernetesDeploymentAttributes},
+	{Name: "kubernetes_manifest", SchemaVersion: 1, Tier: 2, Attributes: generateKubernetesManifestAttributes},
+	{Name: "helm_release", SchemaVersion: 1, Tier: 2, Attributes: generateHelmReleaseAttributes},
+}
+
+func generateResourceType() resourceType {
+	return resourceTypes[rand.IntN(len(resourceTypes))]
+}
+
+// legacyResourceTypes are the resource types of the catalog that appear in
+// state written by terraform 0.11. The kubernetes_manifest and helm
+// resources, and the azurerm and google resources as they are generated
+// today, all postdate format version 3.
+var legacyResourceTypes = slices.DeleteFunc(slices.Clone(resourceTypes), func(rt resourceType) bool {
+	return !strings.HasPrefix(rt.Name, "aws_")
+})
+
+func generateLegacyResourceType() resourceType {
+	return legacyResourceTypes[rand.IntN(len(legacyResourceTypes))]
+}
+
+// generateResourceTypes picks count resource types, ordered by tier so that
+// every resource is generated after the resources it may refer to
+func generateResourceTypes(count int) []resourceType {
+	types := make([]resourceType, count)
+	for i := range types {
+		types[i] = generateResourceType()
+	}
+	slices.SortStableFunc(types, func(a, b resourceType) int {
+		return a.Tier - b.Tier
+	})
+	return types
+}
+
+// awsIdentity returns an identity function that copies the given attributes
+// and adds the account ID the resource lives in, along with its region for
+// regional resources. The region comes from the "region" attribute, so the
+// identity always agrees with the attributes.
+func awsIdentity(keys ...string) func(awsLocation, map[string]any) map[string]any {
+	return func(location awsLocation, attributes map[string]any) map[string]any {
+		identity := make(map[string]any)
+		for _, key := range keys {
+			identity[key] = attributes[key]
+		}
+		if region, ok := attributes["region"].(string); ok {
+			identity["region"] = region
+		}
+		identity["account_id"] = location.AccountID
+		return identity
+	}
+}
+
+// awsARNIdentity is the identity used by resources that are identified by
+// their ARN alone
+func awsARNIdentity(_ awsLocation, attributes map[string]any) map[string]any {
+	return map[string]any{
+		"arn": attributes["arn"],
+	}
+}
+
+// arnAccountID returns the account ID component of the "arn" attribute, if any
+func arnAccountID(attributes map[string]any) string {
+	arn, ok := attributes["arn"].(string)
+	if !ok {
+		return ""
+	}
+	// arn:partition:service:region:account-id:resource
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 {
+		return ""
+	}
+	return parts[4]
+}
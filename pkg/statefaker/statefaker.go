@@ -16,32 +16,45 @@ type StateV4 struct {
 	Lineage          string                     `json:"lineage"`
 	Outputs          map[string]json.RawMessage `json:"outputs"`
 	Resources        []ResourceV4               `json:"resources"`
-	Source           string                     `json:"source,omitempty"`
+	// CheckResults holds CheckResultsV4 values. Terraform 1.5 and later
+	// always write it, as null when there are no results, and it is empty
+	// for states without check results.
+	CheckResults json.RawMessage `json:"check_results,omitempty"`
+	Source       string          `json:"source,omitempty"`
 }
 
 type ResourceV4 struct {
-	Module    string       `json:"module,omitempty"`
-	Mode      string       `json:"mode"`
-	Type      string       `json:"type"`
-	Name      string       `json:"name"`
+	Module string `json:"module,omitempty"`
+	Mode   string `json:"mode"`
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	// Each is "list" or "map" in states written by terraform 0.12
+	Each      string       `json:"each,omitempty"`
 	Provider  string       `json:"provider"`
 	Instances []InstanceV4 `json:"instances"`
 }
 
 type InstanceV4 struct {
-	IndexKey              string          `json:"index_key,omitempty"`
-	SchemaVersion         int             `json:"schema_version"`
-	Attributes            json.RawMessage `json:"attributes" faker:"tfattributes"`
-	SensitiveAttributes   []string        `json:"sensitive_attributes" faker:"tfemptystringslice"`
-	IdentitySchemaVersion int             `json:"identity_schema_version" faker:"oneof: 0,1"`
-	Identity              json.RawMessage `json:"identity,omitempty" faker:"tfidentity"`
-	Private               string          `json:"private,omitempty" faker:"tfprivate"`
-	Dependencies          []string        `json:"dependencies,omitempty" faker:"tfdependencies"`
+	// IndexKey is a number for resources using count, a string for
+	// resources using for_each and nil otherwise
+	IndexKey              any               `json:"index_key,omitempty"`
+	Status                string            `json:"status,omitempty"`
+	Deposed               string            `json:"deposed,omitempty"`
+	SchemaVersion         int               `json:"schema_version"`
+	Attributes            json.RawMessage   `json:"attributes,omitempty"`
+	AttributesFlat        map[string]string `json:"attributes_flat,omitempty"`
+	SensitiveAttributes   json.RawMessage   `json:"sensitive_attributes,omitempty"`
+	IdentitySchemaVersion *int              `json:"identity_schema_version,omitempty"`
+	Identity              json.RawMessage   `json:"identity,omitempty"`
+	Private               string            `json:"private,omitempty"`
+	Dependencies          []string          `json:"dependencies,omitempty"`
+	CreateBeforeDestroy   bool              `json:"create_before_destroy,omitempty"`
 }
 
 type OutputV4 struct {
-	Value json.RawMessage `json:"value"`
-	Type  json.RawMessage `json:"type"`
+	Value     json.RawMessage `json:"value"`
+	Type      json.RawMessage `json:"type"`
+	Sensitive bool            `json:"sensitive,omitempty"`
 }
 
 type ExampleAttributes struct {
@@ -53,22 +66,36 @@ func NewFakeStateV4(opts ...Option) (*StateV4, error) {
 	// Apply options with defaults
 	options := ApplyOptions(opts...)
 
+	g, err := newGenerator(options)
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate multiple realistic resources
 	var resourcesCollection []ResourceV4
 
-	for i := 0; i < options.NumResources; i++ {
+	// Resources that others can refer to are generated first
+	for _, rt := range generateResourceTypes(options.NumResources) {
 		mode := "managed"
 		// 1 in 5 chance to be a data resource
 		if rand.IntN(5) == 0 {
 			mode = "data"
 		}
 
-		resourceType := generateResourceType()
-
 		// Configurable chance to have a module address
 		var moduleAddress string
 		if rand.IntN(100) < options.ModuleChance {
-			moduleAddress = generateModuleAddress()
+			moduleAddress = g.modules[rand.IntN(len(g.modules))]
+		}
+
+		configuration, aliased := g.providers.configuration(rt.Name)
+		location := g.locations.location(configuration, aliased)
+		resource := ResourceV4{
+			Mode:     mode,
+			Type:     rt.Name,
+			Name:     g.resourceNames.name(),
+			Module:   moduleAddress,
+			Provider: providerAddress(moduleAddress, configuration),
 		}
 
 		// Generate instances - configurable chance to have multiple instances
@@ -81,17 +108,14 @@ func NewFakeStateV4(opts ...Option) (*StateV4, error) {
 		}
 
 		for j := 0; j < numInstances; j++ {
-			var instance InstanceV4
-			err := faker.FakeData(&instance)
+			instance, err := g.generateInstance(mode, rt, resourceAddress(resource), location)
 			if err != nil {
-				return nil, fmt.Errorf("failed to fake data for managed resource instance: %w", err)
+				return nil, err
 			}
 
 			// Set unique IndexKey for multiple instances
 			if numInstances > 1 {
 				instance.IndexKey = fmt.Sprintf("%s-%s-%d", faker.Word(fakeroptions.WithGenerateUniqueValues(true)), faker.Word(), j)
-			} else {
-				instance.IndexKey = ""
 			}
 
 			instances = append(instances, instance)
@@ -99,15 +123,7 @@ func NewFakeStateV4(opts ...Option) (*StateV4, error) {
 
 		faker.ResetUnique()
 
-		resource := ResourceV4{
-			Mode:      mode,
-			Type:      resourceType,
-			Name:      generateResourceName(),
-			Module:    moduleAddress,
-			Provider:  generateProviderString(resourceType, moduleAddress),
-			Instances: instances,
-		}
-
+		resource.Instances = instances
 		resourcesCollection = append(resourcesCollection, resource)
 	}
 
@@ -115,18 +131,21 @@ func NewFakeStateV4(opts ...Option) (*StateV4, error) {
 
 	// Generate realistic outputs
 	outputsMap := make(map[string]json.RawMessage)
+	var outputNames []string
 
 	for range options.NumOutputs {
-		b, err := generateOutput()
+		b, err := generateOutput(g.locations.primary(), g.cloudNames)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate random output: %w", err)
 		}
-		outputsMap[fmt.Sprintf("%s_%s_%d", faker.Word(), faker.Word(), faker.UnixTime())] = b
+		name := g.outputNames.name()
+		outputsMap[name] = b
+		outputNames = append(outputNames, name)
 	}
 
 	state := &StateV4{
 		Version:          4,
-		TerraformVersion: "1.13.2",
+		TerraformVersion: g.options.TerraformVersion,
 		Serial:           1,
 		Lineage:          faker.UUIDHyphenated(),
 		Outputs:          outputsMap,
@@ -134,5 +153,18 @@ func NewFakeStateV4(opts ...Option) (*StateV4, error) {
 		Source:           "statefaker",
 	}
 
+	if len(options.Anomalies) > 0 {
+		if err := applyAnomalies(state, options.Anomalies, options.AnomalyChance); err != nil {
+			return nil, fmt.Errorf("failed to apply anomalies: %w", err)
+		}
+	}
+
+	if g.version.supportsCheckResults() {
+		state.CheckResults, err = json.Marshal(generateCheckResults(resourcesCollection, outputNames))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal check results: %w", err)
+		}
+	}
+
 	return state, nil
 }
@@ -70,3 +70,92 @@ func TestStateValid(t *testing.T) {
 	t.Logf("terraform state list succeeded with %d total instances from %d unique resources:\n%s",
 		len(resourceLines), len(uniqueResources), outputStr)
 }
+
+func TestInstanceIdentityMatchesAttributes(t *testing.T) {
+	state, err := NewFakeStateV4(WithResources(200))
+	if err != nil {
+		t.Fatalf("failed to generate fake state: %v", err)
+	}
+
+	for _, resource := range state.Resources {
+		for _, instance := range resource.Instances {
+			if instance.Identity == nil {
+				if instance.IdentitySchemaVersion != nil {
+					t.Errorf("%s.%s has identity_schema_version but no identity", resource.Type, resource.Name)
+				}
+				continue
+			}
+			if resource.Mode == "data" {
+				t.Errorf("data.%s.%s has an identity", resource.Type, resource.Name)
+			}
+			if instance.IdentitySchemaVersion == nil {
+				t.Errorf("%s.%s has identity but no identity_schema_version", resource.Type, resource.Name)
+			}
+
+			var attributes, identity map[string]any
+			if err := json.Unmarshal(instance.Attributes, &attributes); err != nil {
+				t.Fatalf("failed to unmarshal attributes: %v", err)
+			}
+			if err := json.Unmarshal(instance.Identity, &identity); err != nil {
+				t.Fatalf("failed to unmarshal identity: %v", err)
+			}
+			for key, value := range identity {
+				if key == "account_id" {
+					continue
+				}
+				if attributes[key] != value {
+					t.Errorf("%s.%s identity %q is %v, but attribute is %v", resource.Type, resource.Name, key, value, attributes[key])
+				}
+			}
+		}
+	}
+}
+
+func TestTerraformVersionFeatures(t *testing.T) {
+	cases := []struct {
+		version             string
+		provider            string
+		sensitiveAttributes bool
+		identity            bool
+	}{
+		{"0.12.31", "provider.", false, false},
+		{"0.13.7", `provider["`, false, false},
+		{"1.4.6", `provider["`, true, false},
+		{"1.12.0", `provider["`, true, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.version, func(t *testing.T) {
+			state, err := NewFakeStateV4(
+				WithResources(50),
+				WithTerraformVersion(c.version),
+			)
+			if err != nil {
+				t.Fatalf("failed to generate fake state: %v", err)
+			}
+			if state.TerraformVersion != c.version {
+				t.Errorf("expected terraform_version %q, got %q", c.version, state.TerraformVersion)
+			}
+
+			var sawIdentity bool
+			for _, resource := range state.Resources {
+				if configuration := strings.TrimPrefix(resource.Provider, resource.Module+"."); !strings.HasPrefix(configuration, c.provider) {
+					t.Errorf("expected %s to use a provider address starting %s, got %s", resourceAddress(resource), c.provider, resource.Provider)
+				}
+				for _, instance := range resource.Instances {
+					if (instance.SensitiveAttributes != nil) != c.sensitiveAttributes {
+						t.Errorf("unexpected sensitive_attributes %s", instance.SensitiveAttributes)
+					}
+					sawIdentity = sawIdentity || instance.Identity != nil
+				}
+			}
+			if sawIdentity != c.identity {
+				t.Errorf("expected identity %t, got %t", c.identity, sawIdentity)
+			}
+		})
+	}
+
+	if _, err := NewFakeStateV4(WithTerraformVersion("0.11.14")); err == nil {
+		t.Error("expected an error generating format version 4 for terraform 0.11")
+	}
+}
@@ -0,0 +1,305 @@
+package statefaker
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-faker/faker/v4"
+)
+
+// StateV3 is the legacy state format written by terraform 0.11 and earlier
+type StateV3 struct {
+	Version          int             `json:"version"`
+	TerraformVersion string          `json:"terraform_version"`
+	Serial           int             `json:"serial"`
+	Lineage          string          `json:"lineage"`
+	Modules          []ModuleStateV3 `json:"modules"`
+}
+
+type ModuleStateV3 struct {
+	Path      []string                   `json:"path"`
+	Outputs   map[string]OutputStateV3   `json:"outputs"`
+	Resources map[string]ResourceStateV3 `json:"resources"`
+	DependsOn []string                   `json:"depends_on"`
+}
+
+type OutputStateV3 struct {
+	Sensitive bool   `json:"sensitive"`
+	Type      string `json:"type"`
+	Value     any    `json:"value"`
+}
+
+type ResourceStateV3 struct {
+	Type      string             `json:"type"`
+	DependsOn []string           `json:"depends_on"`
+	Primary   *InstanceStateV3   `json:"primary"`
+	Deposed   []*InstanceStateV3 `json:"deposed"`
+	Provider  string             `json:"provider"`
+}
+
+type InstanceStateV3 struct {
+	ID         string            `json:"id"`
+	Attributes map[string]string `json:"attributes"`
+	Meta       map[string]any    `json:"meta"`
+	Tainted    bool              `json:"tainted"`
+}
+
+// NewFakeStateV3 generates a legacy format version 3 state. Multi-instance
+// resources use count, the only form of repetition available before 0.12,
+// and resources in modules are grouped into per-module entries. Only
+// resource types that existed before 0.12 are generated, and provider
+// private data is the SDK timeouts kept in each instance's meta. Anomalies
+// are specific to format version 4, so they cannot be combined with it.
+func NewFakeStateV3(opts ...Option) (*StateV3, error) {
+	// Apply options with defaults
+	options := ApplyOptions(opts...)
+
+	if options.TerraformVersion == "" {
+		options.TerraformVersion = DefaultTerraformVersionV3
+	}
+	version, err := parseTerraformVersion(options.TerraformVersion)
+	if err != nil {
+		return nil, err
+	}
+	if version.supportsStateV4() {
+		return nil, fmt.Errorf("terraform %s writes state format version 4, format version 3 requires terraform 0.11 or earlier", options.TerraformVersion)
+	}
+	if len(options.Anomalies) > 0 {
+		return nil, fmt.Errorf("anomalies are only generated for state format version 4")
+	}
+
+	providers := newProviderRegistry(options, version)
+	locations := newLocationRegistry(options)
+	resourceNames := newNameSequence(resourceNameParts, options.NameStyle)
+	outputNames := newNameSequence(outputNameParts, options.NameStyle)
+	cloudNames := newCloudNameSequence(options.NameStyle)
+	moduleChoices := moduleAddresses(options.NameStyle, 8)
+	tagger := newTagger(options)
+	root := newModuleStateV3([]string{"root"})
+	modules := map[string]*ModuleStateV3{"": root}
+
+	for i := 0; i < options.NumResources; i++ {
+		rt := generateLegacyResourceType()
+
+		// Configurable chance to have a module address
+		var moduleAddress string
+		if rand.IntN(100) < options.ModuleChance {
+			moduleAddress = moduleChoices[rand.IntN(len(moduleChoices))]
+		}
+		module, ok := modules[moduleAddress]
+		if !ok {
+			module = newModuleStateV3([]string{"root", strings.TrimPrefix(moduleAddress, "module.")})
+			modules[moduleAddress] = module
+		}
+
+		key := fmt.Sprintf("%s.%s", rt.Name, resourceNames.name())
+		// 1 in 5 chance to be a data resource
+		if rand.IntN(5) == 0 {
+			key = "data." + key
+		}
+
+		// Configurable chance to have multiple instances, using count
+		numInstances := 1
+		if rand.IntN(100) < options.MultiInstanceChance {
+			instanceRange := options.MultiInstanceMax - options.MultiInstanceMin + 1
+			numInstances = rand.IntN(instanceRange) + options.MultiInstanceMin
+		}
+
+		dependsOn := generateDependsOnV3(module)
+		provider, aliased := providers.configuration(rt.Name)
+		location := locations.location(provider, aliased)
+
+		for j := 0; j < numInstances; j++ {
+			instanceKey := key
+			if numInstances > 1 {
+				instanceKey = fmt.Sprintf("%s.%d", key, j)
+			}
+			primary, err := generateInstanceStateV3(rt, options, newAttributeContext(locations, location, nil, tagger, cloudNames))
+			if err != nil {
+				return nil, err
+			}
+			module.Resources[instanceKey] = ResourceStateV3{
+				Type:      rt.Name,
+				DependsOn: dependsOn,
+				Primary:   primary,
+				Deposed:   []*InstanceStateV3{},
+				Provider:  provider,
+			}
+		}
+	}
+
+	for range options.NumOutputs {
+		name := outputNames.name()
+		root.Outputs[name] = generateOutputStateV3(locations.primary())
+	}
+
+	state := &StateV3{
+		Version:          3,
+		TerraformVersion: options.TerraformVersion,
+		Serial:           1,
+		Lineage:          faker.UUIDHyphenated(),
+		Modules:          []ModuleStateV3{*root},
+	}
+
+	// Child modules follow the root module, ordered by path
+	var moduleAddresses []string
+	for address := range modules {
+		if address != "" {
+			moduleAddresses = append(moduleAddresses, address)
+		}
+	}
+	sort.Strings(moduleAddresses)
+	for _, address := range moduleAddresses {
+		state.Modules = append(state.Modules, *modules[address])
+	}
+
+	return state, nil
+}
+
+func newModuleStateV3(path []string) *ModuleStateV3 {
+	return &ModuleStateV3{
+		Path:      path,
+		Outputs:   make(map[string]OutputStateV3),
+		Resources: make(map[string]ResourceStateV3),
+		DependsOn: []string{},
+	}
+}
+
+// generateDependsOnV3 picks up to two resources already in the module for a
+// new resource to depend on
+func generateDependsOnV3(module *ModuleStateV3) []string {
+	numDeps := rand.IntN(3)
+	dependsOn := []string{}
+	seen := make(map[string]bool)
+	for key := range module.Resources {
+		if len(dependsOn) == numDeps {
+			break
+		}
+		// Dependencies refer to the resource rather than a counted instance
+		parts := strings.Split(key, ".")
+		if _, err := strconv.Atoi(parts[len(parts)-1]); err == nil {
+			key = strings.Join(parts[:len(parts)-1], ".")
+		}
+		if !seen[key] {
+			seen[key] = true
+			dependsOn = append(dependsOn, key)
+		}
+	}
+	return dependsOn
+}
+
+// generateInstanceStateV3 generates the primary instance of a resource. The
+// context must have no reference pool, since format version 3 records
+// dependencies with depends_on instead of attributes referring to other
+// resources.
+func generateInstanceStateV3(rt resourceType, options Options, ctx *attributeContext) (*InstanceStateV3, error) {
+	generated := rt.Attributes(ctx)
+	// Providers of the 0.11 era had no default_tags, so no tags_all either
+	delete(generated, "tags_all")
+	if size := sampleAttributeSize(options.AttributeSize, options.AttributeSizeMax); size > 0 {
+		if err := padAttributes(ctx, rt.Name, generated, size); err != nil {
+			return nil, fmt.Errorf("failed to pad %s attributes: %w", rt.Name, err)
+		}
+	}
+	attributes := make(map[string]string)
+	flattenAttributes(attributes, "", generated)
+
+	// Like SDKv2 private data, meta holds the schema version, when there is
+	// one, and any configured timeouts
+	meta := make(map[string]any)
+	if rt.SchemaVersion > 0 {
+		meta["schema_version"] = fmt.Sprint(rt.SchemaVersion)
+	}
+	if rand.IntN(100) < options.PrivateChance {
+		meta[sdkTimeoutsKey] = map[string]int64{
+			"create": 600000000000,
+			"delete": 600000000000,
+		}
+	}
+
+	return &InstanceStateV3{
+		ID:         attributes["id"],
+		Attributes: attributes,
+		Meta:       meta,
+		Tainted:    rand.IntN(50) == 0,
+	}, nil
+}
+
+// flattenAttributes writes value into attributes using the flatmap encoding
+// of format version 3, where maps record their size under "%", lists record
+// their length under "#" and every primitive is a string
+func flattenAttributes(attributes map[string]string, prefix string, value any) {
+	key := func(k string) string {
+		if prefix == "" {
+			return k
+		}
+		return prefix + "." + k
+	}
+
+	switch v := value.(type) {
+	case nil:
+		// Null values are omitted
+	case map[string]any:
+		// Nested blocks are flattened without recording their size
+		for k, item := range v {
+			flattenAttributes(attributes, key(k), item)
+		}
+	case map[string]string:
+		attributes[key("%")] = strconv.Itoa(len(v))
+		for k, item := range v {
+			attributes[key(k)] = item
+		}
+	case []map[string]any:
+		attributes[key("#")] = strconv.Itoa(len(v))
+		for i, item := range v {
+			flattenAttributes(attributes, key(strconv.Itoa(i)), item)
+		}
+	case []string:
+		attributes[key("#")] = strconv.Itoa(len(v))
+		for i, item := range v {
+			attributes[key(strconv.Itoa(i))] = item
+		}
+	case []any:
+		attributes[key("#")] = strconv.Itoa(len(v))
+		for i, item := range v {
+			flattenAttributes(attributes, key(strconv.Itoa(i)), item)
+		}
+	default:
+		attributes[prefix] = fmt.Sprint(v)
+	}
+}
+
+// generateOutputStateV3 generates a legacy output. Format version 3 only
+// knows string, list and map outputs, and stores scalars as strings.
+func generateOutputStateV3(location awsLocation) OutputStateV3 {
+	switch rand.IntN(4) {
+	case 0:
+		return OutputStateV3{
+			Type:  "list",
+			Value: []string{generateS3BucketName(), generateS3BucketName()},
+		}
+	case 1:
+		return OutputStateV3{
+			Type: "map",
+			Value: map[string]string{
+				"vpc_id":     fmt.Sprintf("vpc-%s", faker.UUIDDigit()[:17]),
+				"cidr_block": fmt.Sprintf("10.%d.0.0/16", rand.IntN(256)),
+				"region":     location.Region,
+			},
+		}
+	case 2:
+		return OutputStateV3{
+			Sensitive: true,
+			Type:      "string",
+			Value:     faker.Password(),
+		}
+	default:
+		return OutputStateV3{
+			Type:  "string",
+			Value: faker.Sentence(),
+		}
+	}
+}
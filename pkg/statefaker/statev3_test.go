@@ -0,0 +1,106 @@
+package statefaker
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestFlattenAttributes(t *testing.T) {
+	attributes := make(map[string]string)
+	flattenAttributes(attributes, "", map[string]any{
+		"id":      "vpc-123",
+		"enabled": true,
+		"port":    5432,
+		"missing": nil,
+		"tags":    map[string]string{"Team": "data"},
+		"subnets": []string{"subnet-a", "subnet-b"},
+		"ingress": []map[string]any{
+			{"from_port": 443, "cidr_blocks": []string{"0.0.0.0/0"}},
+		},
+	})
+
+	expected := map[string]string{
+		"id":                      "vpc-123",
+		"enabled":                 "true",
+		"port":                    "5432",
+		"tags.%":                  "1",
+		"tags.Team":               "data",
+		"subnets.#":               "2",
+		"subnets.0":               "subnet-a",
+		"subnets.1":               "subnet-b",
+		"ingress.#":               "1",
+		"ingress.0.from_port":     "443",
+		"ingress.0.cidr_blocks.#": "1",
+		"ingress.0.cidr_blocks.0": "0.0.0.0/0",
+	}
+	if !reflect.DeepEqual(attributes, expected) {
+		t.Errorf("unexpected flattened attributes:\n got: %v\nwant: %v", attributes, expected)
+	}
+}
+
+func TestNewFakeStateV3(t *testing.T) {
+	state, err := NewFakeStateV3(WithResources(200), WithOutputs(5), WithPrivateChance(50))
+	if err != nil {
+		t.Fatalf("failed to generate fake state: %v", err)
+	}
+
+	if state.Version != 3 || state.TerraformVersion != DefaultTerraformVersionV3 {
+		t.Errorf("unexpected version %d written by terraform %s", state.Version, state.TerraformVersion)
+	}
+	if len(state.Modules) == 0 || !reflect.DeepEqual(state.Modules[0].Path, []string{"root"}) {
+		t.Fatalf("expected the root module first, got %v", state.Modules)
+	}
+	if len(state.Modules[0].Outputs) != 5 {
+		t.Errorf("expected 5 root outputs, got %d", len(state.Modules[0].Outputs))
+	}
+	for _, module := range state.Modules {
+		for key, resource := range module.Resources {
+			if resource.Primary.ID == "" || resource.Primary.ID != resource.Primary.Attributes["id"] {
+				t.Errorf("%s primary id %q does not match its id attribute %q", key, resource.Primary.ID, resource.Primary.Attributes["id"])
+			}
+			index := slices.IndexFunc(legacyResourceTypes, func(rt resourceType) bool { return rt.Name == resource.Type })
+			if index < 0 {
+				t.Errorf("%s is a %s, which postdates format version 3", key, resource.Type)
+				continue
+			}
+			// Like SDKv2 private data, meta has no schema version for version 0
+			var expected string
+			if version := legacyResourceTypes[index].SchemaVersion; version > 0 {
+				expected = fmt.Sprint(version)
+			}
+			if schemaVersion, _ := resource.Primary.Meta["schema_version"].(string); schemaVersion != expected {
+				t.Errorf("%s has schema version %q, expected %q", key, schemaVersion, expected)
+			}
+		}
+	}
+
+	if _, err := NewFakeStateV3(WithTerraformVersion("0.12.31")); err == nil {
+		t.Error("expected an error generating format version 3 for terraform 0.12")
+	}
+	if _, err := NewFakeStateV3(WithAnomalies(AnomalyHugeNumbers)); err == nil {
+		t.Error("expected an error generating anomalies in format version 3")
+	}
+
+	// Padding applies before attributes are flattened
+	state, err = NewFakeStateV3(WithResources(50), WithMultiInstanceChance(0), WithAttributeSize(16*1024))
+	if err != nil {
+		t.Fatalf("failed to generate fake state: %v", err)
+	}
+	padded := 0
+	for _, module := range state.Modules {
+		for _, resource := range module.Resources {
+			size := 0
+			for key, value := range resource.Primary.Attributes {
+				size += len(key) + len(value)
+			}
+			if size >= 4*1024 {
+				padded++
+			}
+		}
+	}
+	if padded == 0 {
+		t.Error("expected some instances to be padded")
+	}
+}
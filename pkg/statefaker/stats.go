@@ -0,0 +1,201 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Stats summarizes the contents of a state, to confirm that generation
+// options had the intended effect or to profile an existing state
+type Stats struct {
+	Resources int `json:"resources"`
+	Instances int `json:"instances"`
+	Outputs   int `json:"outputs"`
+
+	// TaintedInstances are instances terraform will replace on the next
+	// apply, and DeposedObjects are objects left behind by interrupted
+	// create_before_destroy replacements, which are not counted as instances
+	TaintedInstances int `json:"tainted_instances"`
+	DeposedObjects   int `json:"deposed_objects"`
+
+	// Resource counts keyed by type, mode, provider and module. Resources in
+	// the root module are counted under "root".
+	ResourcesByType     map[string]int `json:"resources_by_type"`
+	ResourcesByMode     map[string]int `json:"resources_by_mode"`
+	ResourcesByProvider map[string]int `json:"resources_by_provider"`
+	ResourcesByModule   map[string]int `json:"resources_by_module"`
+
+	// InstancesPerResource counts resources by their number of instances
+	InstancesPerResource map[int]int `json:"instances_per_resource"`
+
+	// OutputsByType counts outputs by the kind of their type, such as string,
+	// list or object
+	OutputsByType    map[string]int `json:"outputs_by_type"`
+	SensitiveOutputs int            `json:"sensitive_outputs"`
+	// SensitiveInstances is the number of instances with at least one
+	// sensitive attribute path, and SensitivePaths the total number of paths
+	SensitiveInstances int `json:"sensitive_instances"`
+	SensitivePaths     int `json:"sensitive_paths"`
+
+	// AttributeSizes holds percentiles of the size in bytes of each
+	// instance's attributes
+	AttributeSizes Percentiles `json:"attribute_sizes"`
+
+	// LargestResources are the resources with the largest attributes, summed
+	// over their instances, largest first
+	LargestResources []ResourceSize `json:"largest_resources"`
+}
+
+// Percentiles of a set of sizes
+type Percentiles struct {
+	P50 int `json:"p50"`
+	P90 int `json:"p90"`
+	P99 int `json:"p99"`
+	Max int `json:"max"`
+}
+
+// ResourceSize is the total size in bytes of a resource's attributes
+type ResourceSize struct {
+	Address string `json:"address"`
+	Bytes   int    `json:"bytes"`
+}
+
+// numLargestResources is the number of resources listed in
+// Stats.LargestResources
+const numLargestResources = 10
+
+// NewStats computes the statistics of a state
+func NewStats(state *StateV4) (*Stats, error) {
+	stats := &Stats{
+		Resources:            len(state.Resources),
+		Outputs:              len(state.Outputs),
+		ResourcesByType:      make(map[string]int),
+		ResourcesByMode:      make(map[string]int),
+		ResourcesByProvider:  make(map[string]int),
+		ResourcesByModule:    make(map[string]int),
+		InstancesPerResource: make(map[int]int),
+		OutputsByType:        make(map[string]int),
+	}
+
+	var sizes []int
+	for _, resource := range state.Resources {
+		stats.ResourcesByType[resource.Type]++
+		stats.ResourcesByMode[resource.Mode]++
+		stats.ResourcesByProvider[resource.Provider]++
+		module := resource.Module
+		if module == "" {
+			module = "root"
+		}
+		stats.ResourcesByModule[module]++
+
+		instances := 0
+		total := 0
+		for _, instance := range resource.Instances {
+			switch {
+			case instance.Deposed != "":
+				stats.DeposedObjects++
+			case instance.Status == "tainted":
+				stats.TaintedInstances++
+				fallthrough
+			default:
+				instances++
+			}
+
+			size := len(instance.Attributes)
+			if size == 0 && len(instance.AttributesFlat) > 0 {
+				// Legacy flatmap attributes of states upgraded from
+				// terraform 0.11 that have not been refreshed since
+				flat, err := json.Marshal(instance.AttributesFlat)
+				if err != nil {
+					return nil, fmt.Errorf("failed to encode attributes of %s: %w", resourceAddress(resource), err)
+				}
+				size = len(flat)
+			}
+			sizes = append(sizes, size)
+			total += size
+
+			var paths []json.RawMessage
+			if len(instance.SensitiveAttributes) > 0 {
+				if err := json.Unmarshal(instance.SensitiveAttributes, &paths); err != nil {
+					return nil, fmt.Errorf("failed to decode sensitive attributes of %s: %w", resourceAddress(resource), err)
+				}
+			}
+			if len(paths) > 0 {
+				stats.SensitiveInstances++
+				stats.SensitivePaths += len(paths)
+			}
+		}
+		stats.InstancesPerResource[instances]++
+		stats.Instances += instances
+		stats.LargestResources = append(stats.LargestResources, ResourceSize{Address: resourceAddress(resource), Bytes: total})
+	}
+
+	slices.SortStableFunc(stats.LargestResources, func(a, b ResourceSize) int {
+		return b.Bytes - a.Bytes
+	})
+	if len(stats.LargestResources) > numLargestResources {
+		stats.LargestResources = stats.LargestResources[:numLargestResources]
+	}
+
+	slices.Sort(sizes)
+	stats.AttributeSizes = Percentiles{
+		P50: percentile(sizes, 50),
+		P90: percentile(sizes, 90),
+		P99: percentile(sizes, 99),
+		Max: percentile(sizes, 100),
+	}
+
+	outputs, err := decodeOutputs(state)
+	if err != nil {
+		return nil, err
+	}
+	for _, output := range outputs {
+		stats.OutputsByType[typeKind(output.Type)]++
+		if output.Sensitive {
+			stats.SensitiveOutputs++
+		}
+	}
+
+	return stats, nil
+}
+
+// resourceAddress returns the absolute address of a resource
+func resourceAddress(resource ResourceV4) string {
+	var address strings.Builder
+	if resource.Module != "" {
+		address.WriteString(resource.Module + ".")
+	}
+	if resource.Mode == "data" {
+		address.WriteString("data.")
+	}
+	address.WriteString(resource.Type + "." + resource.Name)
+	return address.String()
+}
+
+// percentile returns the nearest rank percentile of sorted values
+func percentile(sorted []int, p int) int {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// typeKind returns the kind of a type in terraform's JSON type encoding:
+// the name of a primitive type, or the first element of a complex type such
+// as ["list","string"]
+func typeKind(t json.RawMessage) string {
+	var primitive string
+	if err := json.Unmarshal(t, &primitive); err == nil {
+		return primitive
+	}
+	var complex []json.RawMessage
+	if err := json.Unmarshal(t, &complex); err == nil && len(complex) > 0 {
+		if err := json.Unmarshal(complex[0], &primitive); err == nil {
+			return primitive
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,93 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewStats(t *testing.T) {
+	state := &StateV4{
+		Version: 4,
+		Resources: []ResourceV4{
+			{
+				Mode:     "managed",
+				Type:     "aws_vpc",
+				Name:     "main",
+				Provider: `provider["registry.terraform.io/hashicorp/aws"]`,
+				Instances: []InstanceV4{
+					{Attributes: json.RawMessage(`{"id":"vpc-1"}`)},
+				},
+			},
+			{
+				Module:   "module.db",
+				Mode:     "managed",
+				Type:     "aws_db_instance",
+				Name:     "primary",
+				Provider: `module.db.provider["registry.terraform.io/hashicorp/aws"]`,
+				Instances: []InstanceV4{
+					{IndexKey: "a", Attributes: json.RawMessage(`{"id":"db-1","password":"hunter2"}`), SensitiveAttributes: json.RawMessage(`[[{"type":"get_attr","value":"password"}]]`)},
+					{IndexKey: "b", Attributes: json.RawMessage(`{"id":"db-2","password":"hunter3"}`), SensitiveAttributes: json.RawMessage(`[[{"type":"get_attr","value":"password"}]]`)},
+				},
+			},
+			{
+				Mode:     "data",
+				Type:     "aws_vpc",
+				Name:     "default",
+				Provider: `provider["registry.terraform.io/hashicorp/aws"]`,
+				Instances: []InstanceV4{
+					{Attributes: json.RawMessage(`{"id":"vpc-0"}`)},
+				},
+			},
+		},
+		Outputs: map[string]json.RawMessage{
+			"vpc_id":   json.RawMessage(`{"value":"vpc-1","type":"string"}`),
+			"db_ids":   json.RawMessage(`{"value":["db-1","db-2"],"type":["list","string"]}`),
+			"password": json.RawMessage(`{"value":"hunter2","type":"string","sensitive":true}`),
+		},
+	}
+
+	stats, err := NewStats(state)
+	if err != nil {
+		t.Fatalf("failed to compute stats: %v", err)
+	}
+
+	if stats.Resources != 3 || stats.Instances != 4 || stats.Outputs != 3 {
+		t.Errorf("expected 3 resources, 4 instances and 3 outputs, got %d, %d and %d", stats.Resources, stats.Instances, stats.Outputs)
+	}
+	if stats.ResourcesByType["aws_vpc"] != 2 || stats.ResourcesByMode["data"] != 1 || stats.ResourcesByModule["root"] != 2 {
+		t.Errorf("unexpected resource counts: %v %v %v", stats.ResourcesByType, stats.ResourcesByMode, stats.ResourcesByModule)
+	}
+	if stats.InstancesPerResource[1] != 2 || stats.InstancesPerResource[2] != 1 {
+		t.Errorf("unexpected instance distribution: %v", stats.InstancesPerResource)
+	}
+	if stats.OutputsByType["string"] != 2 || stats.OutputsByType["list"] != 1 || stats.SensitiveOutputs != 1 {
+		t.Errorf("unexpected output counts: %v, %d sensitive", stats.OutputsByType, stats.SensitiveOutputs)
+	}
+	if stats.SensitiveInstances != 2 || stats.SensitivePaths != 2 {
+		t.Errorf("expected 2 sensitive instances and paths, got %d and %d", stats.SensitiveInstances, stats.SensitivePaths)
+	}
+	if stats.AttributeSizes.Max != len(`{"id":"db-1","password":"hunter2"}`) {
+		t.Errorf("unexpected attribute sizes: %+v", stats.AttributeSizes)
+	}
+	if stats.LargestResources[0].Address != "module.db.aws_db_instance.primary" {
+		t.Errorf("expected the database to be the largest resource, got %s", stats.LargestResources[0].Address)
+	}
+	if stats.LargestResources[2].Address != "data.aws_vpc.default" {
+		t.Errorf("expected a data resource address, got %s", stats.LargestResources[2].Address)
+	}
+}
+
+func TestNewStatsSensitiveOutputs(t *testing.T) {
+	state, err := NewFakeStateV4(WithResources(5), WithOutputs(200))
+	if err != nil {
+		t.Fatalf("failed to generate state: %v", err)
+	}
+
+	stats, err := NewStats(state)
+	if err != nil {
+		t.Fatalf("failed to compute stats: %v", err)
+	}
+	if stats.SensitiveOutputs == 0 || stats.SensitiveOutputs == stats.Outputs {
+		t.Errorf("expected some but not all of %d outputs to be sensitive, got %d", stats.Outputs, stats.SensitiveOutputs)
+	}
+}
@@ -0,0 +1,140 @@
+package statefaker
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/go-faker/faker/v4"
+)
+
+// tagKey is a key of the organizational tag schema, along with how to
+// generate its values
+type tagKey struct {
+	Name string
+	// Value generates a value. Values of keys with low cardinality are drawn
+	// from a small pool chosen once per state.
+	Value func() string
+	// PoolSize is the largest number of distinct values a low cardinality key
+	// has in one state
+	PoolSize int
+}
+
+// tagSchema is the organizational tag schema shared by every resource
+var tagSchema = []tagKey{
+	{Name: "Environment", PoolSize: 1, Value: func() string { return workspaceEnvs[rand.IntN(len(workspaceEnvs))] }},
+	{Name: "Team", PoolSize: 3, Value: func() string { return workspaceTeams[rand.IntN(len(workspaceTeams))] }},
+	{Name: "Owner", PoolSize: 5, Value: func() string { return strings.ToLower(faker.Username()) + "@example.com" }},
+	{Name: "CostCenter", PoolSize: 3, Value: func() string { return fmt.Sprintf("CC-%04d", rand.IntN(10000)) }},
+	{Name: "Project", PoolSize: 3, Value: func() string { return faker.Word() + "-" + faker.Word() }},
+	{Name: "Application", PoolSize: 4, Value: func() string { return faker.Word() }},
+	{Name: "Service", PoolSize: 6, Value: func() string { return faker.Word() + "-svc" }},
+	{Name: "ManagedBy", PoolSize: 1, Value: func() string { return "terraform" }},
+	{Name: "DataClassification", PoolSize: 4, Value: func() string {
+		return []string{"public", "internal", "confidential", "restricted"}[rand.IntN(4)]
+	}},
+	{Name: "Compliance", PoolSize: 2, Value: func() string { return []string{"pci", "hipaa", "sox", "gdpr", "none"}[rand.IntN(5)] }},
+	{Name: "BackupPolicy", PoolSize: 3, Value: func() string { return []string{"daily", "weekly", "monthly", "none"}[rand.IntN(4)] }},
+	{Name: "Repository", PoolSize: 1, Value: func() string { return fmt.Sprintf("github.com/%s/%s", faker.Word(), faker.Word()) }},
+	{Name: "Version", PoolSize: 4, Value: func() string { return fmt.Sprintf("v%d.%d.%d", rand.IntN(5), rand.IntN(20), rand.IntN(50)) }},
+}
+
+// highCardinalityTagSchema are tags whose values are different on nearly
+// every resource
+var highCardinalityTagSchema = []tagKey{
+	{Name: "GitCommit", Value: func() string { return (faker.UUIDDigit() + faker.UUIDDigit())[:40] }},
+	{Name: "BuildId", Value: func() string { return faker.UUIDHyphenated() }},
+	{Name: "DeployedAt", Value: func() string {
+		return time.Unix(int64(legacyEpoch+rand.IntN(200000000)), 0).UTC().Format(time.RFC3339)
+	}},
+	{Name: "TraceId", Value: func() string { return faker.UUIDDigit() }},
+	{Name: "ChangeTicket", Value: func() string { return fmt.Sprintf("CHG%07d", rand.IntN(10000000)) }},
+}
+
+// defaultTagKeys are the keys that are usually applied to every resource by
+// the provider's default_tags
+var defaultTagKeys = []string{"Environment", "ManagedBy", "Repository", "CostCenter", "Owner", "Project"}
+
+// tagger generates the tags of every resource in a state, so that they share
+// the organization's tag schema, values and provider default_tags
+type tagger struct {
+	options  Options
+	keys     []string
+	pools    map[string][]string
+	defaults map[string]string
+}
+
+func newTagger(options Options) *tagger {
+	t := &tagger{
+		options:  options,
+		pools:    make(map[string][]string),
+		defaults: make(map[string]string),
+	}
+	for _, key := range tagSchema {
+		t.keys = append(t.keys, key.Name)
+		for range rand.IntN(key.PoolSize) + 1 {
+			t.pools[key.Name] = append(t.pools[key.Name], key.Value())
+		}
+	}
+	for _, i := range rand.Perm(len(defaultTagKeys))[:min(options.DefaultTagCount, len(defaultTagKeys))] {
+		t.defaults[defaultTagKeys[i]] = t.value(defaultTagKeys[i])
+	}
+
+	// Organizations that tag heavily add their own namespaced keys beyond
+	// the common schema, so there are always enough keys for TagCountMax
+	// tags besides the default ones
+	prefix := faker.Word()
+	for len(t.keys)-len(t.defaults) < options.TagCountMax {
+		key := fmt.Sprintf("%s:%s-%s", prefix, faker.Word(), faker.Word())
+		if _, ok := t.pools[key]; ok {
+			continue
+		}
+		t.keys = append(t.keys, key)
+		for range rand.IntN(3) + 1 {
+			t.pools[key] = append(t.pools[key], faker.Word())
+		}
+	}
+	return t
+}
+
+func (t *tagger) value(key string) string {
+	pool := t.pools[key]
+	return pool[rand.IntN(len(pool))]
+}
+
+// tags returns the tags of a new resource, including any resource specific
+// tags given, and its tags_all: the tags merged over the provider's
+// default_tags. The number of schema tags is skewed towards TagCountMin, so
+// most resources have a few tags and some have many.
+func (t *tagger) tags(specific map[string]string) (tags, tagsAll map[string]string) {
+	tags = make(map[string]string)
+	maps.Copy(tags, specific)
+
+	spread := float64(t.options.TagCountMax - t.options.TagCountMin + 1)
+	count := t.options.TagCountMin + int(spread*math.Pow(rand.Float64(), 2))
+	for _, i := range rand.Perm(len(t.keys)) {
+		if len(tags) >= count {
+			break
+		}
+		key := t.keys[i]
+		// Resources rarely repeat a default tag, and override it when they do
+		if _, ok := t.defaults[key]; ok && rand.IntN(10) != 0 {
+			continue
+		}
+		tags[key] = t.value(key)
+	}
+
+	if rand.IntN(100) < t.options.HighCardinalityTagChance {
+		for _, i := range rand.Perm(len(highCardinalityTagSchema))[:rand.IntN(len(highCardinalityTagSchema))+1] {
+			tags[highCardinalityTagSchema[i].Name] = highCardinalityTagSchema[i].Value()
+		}
+	}
+
+	tagsAll = make(map[string]string, len(t.defaults)+len(tags))
+	maps.Copy(tagsAll, t.defaults)
+	maps.Copy(tagsAll, tags)
+	return tags, tagsAll
+}
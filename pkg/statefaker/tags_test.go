@@ -0,0 +1,132 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		state, err := NewFakeStateV4(WithResources(100), WithDefaultTagCount(3))
+		if err != nil {
+			t.Fatalf("failed to generate fake state: %v", err)
+		}
+
+		defaults := make(map[string]string)
+		tagged := 0
+		for _, resource := range state.Resources {
+			// Only AWS resources have provider default tags
+			if !strings.HasPrefix(resource.Type, "aws_") {
+				continue
+			}
+			for _, instance := range resource.Instances {
+				var attributes struct {
+					Tags    map[string]string `json:"tags"`
+					TagsAll map[string]string `json:"tags_all"`
+				}
+				if err := json.Unmarshal(instance.Attributes, &attributes); err != nil {
+					t.Fatalf("failed to decode attributes: %v", err)
+				}
+				if attributes.TagsAll == nil {
+					continue
+				}
+				tagged++
+
+				// tags_all is the provider's default tags merged with the
+				// resource's own tags
+				instanceDefaults := make(map[string]string)
+				for key, value := range attributes.TagsAll {
+					if _, ok := attributes.Tags[key]; !ok {
+						instanceDefaults[key] = value
+					} else if attributes.Tags[key] != value {
+						t.Errorf("%s tags_all has %s=%s, but tags has %s", resource.Type, key, value, attributes.Tags[key])
+					}
+				}
+				for key := range attributes.Tags {
+					if _, ok := attributes.TagsAll[key]; !ok {
+						t.Errorf("%s tags_all is missing tag %s", resource.Type, key)
+					}
+				}
+
+				// Default tags have the same value on every resource
+				for key, value := range instanceDefaults {
+					if seen, ok := defaults[key]; ok && seen != value {
+						t.Errorf("%s has default tag %s=%s, expected %s", resource.Type, key, value, seen)
+					}
+					defaults[key] = value
+				}
+			}
+		}
+		if tagged == 0 {
+			t.Fatal("expected tagged resources")
+		}
+		if len(defaults) != 3 {
+			t.Errorf("expected 3 default tags, got %v", defaults)
+		}
+	})
+
+	t.Run("counts", func(t *testing.T) {
+		options := ApplyOptions(WithTagCountMin(3), WithTagCountMax(5), WithDefaultTagCount(0), WithHighCardinalityTagChance(0))
+		tagger := newTagger(options)
+		for range 100 {
+			tags, tagsAll := tagger.tags(nil)
+			if len(tags) < 3 || len(tags) > 5 {
+				t.Errorf("expected between 3 and 5 tags, got %v", tags)
+			}
+			if !maps.Equal(tags, tagsAll) {
+				t.Errorf("expected tags_all %v to equal tags %v without default tags", tagsAll, tags)
+			}
+		}
+	})
+
+	t.Run("option order", func(t *testing.T) {
+		for _, opts := range [][]Option{
+			{WithTagCountMin(10), WithTagCountMax(12)},
+			{WithTagCountMax(12), WithTagCountMin(10)},
+		} {
+			if options := ApplyOptions(opts...); options.TagCountMin != 10 || options.TagCountMax != 12 {
+				t.Errorf("expected tag counts between 10 and 12, got %d and %d", options.TagCountMin, options.TagCountMax)
+			}
+		}
+		if options := ApplyOptions(WithTagCountMin(10), WithTagCountMax(4)); options.TagCountMax != 10 {
+			t.Errorf("expected the maximum tag count to be raised to the minimum, got %d", options.TagCountMax)
+		}
+	})
+
+	t.Run("beyond the schema", func(t *testing.T) {
+		options := ApplyOptions(WithTagCountMin(50), WithTagCountMax(50), WithDefaultTagCount(3), WithHighCardinalityTagChance(0))
+		tagger := newTagger(options)
+		for range 20 {
+			if tags, _ := tagger.tags(nil); len(tags) != 50 {
+				t.Errorf("expected 50 tags, got %d", len(tags))
+			}
+		}
+	})
+
+	t.Run("high cardinality", func(t *testing.T) {
+		options := ApplyOptions(WithHighCardinalityTagChance(100))
+		tagger := newTagger(options)
+		for range 20 {
+			tags, _ := tagger.tags(nil)
+			found := false
+			for _, key := range highCardinalityTagSchema {
+				if _, ok := tags[key.Name]; ok {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected high cardinality tags, got %v", tags)
+			}
+		}
+	})
+
+	t.Run("specific", func(t *testing.T) {
+		tagger := newTagger(DefaultOptions())
+		tags, tagsAll := tagger.tags(map[string]string{"Name": "web"})
+		if tags["Name"] != "web" || tagsAll["Name"] != "web" {
+			t.Errorf("expected resource specific tag Name=web, got %v and %v", tags, tagsAll)
+		}
+	})
+}
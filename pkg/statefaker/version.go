@@ -0,0 +1,57 @@
+package statefaker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultTerraformVersionV4 is the terraform version written to format
+	// version 4 states when no version is configured
+	DefaultTerraformVersionV4 = "1.13.2"
+	// DefaultTerraformVersionV3 is the terraform version written to format
+	// version 3 states when no version is configured
+	DefaultTerraformVersionV3 = "0.11.14"
+)
+
+// terraformVersion is a parsed Terraform version, used to decide which state
+// features the targeted version supports
+type terraformVersion struct {
+	Major, Minor, Patch int
+}
+
+// parseTerraformVersion parses versions like 1.5.7, v0.14.0 or 1.6.0-beta1
+func parseTerraformVersion(s string) (terraformVersion, error) {
+	core, _, _ := strings.Cut(strings.TrimPrefix(s, "v"), "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return terraformVersion{}, fmt.Errorf("invalid terraform version %q", s)
+	}
+
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return terraformVersion{}, fmt.Errorf("invalid terraform version %q", s)
+		}
+		numbers[i] = n
+	}
+
+	return terraformVersion{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// AtLeast reports whether the version is the given major and minor version or later
+func (v terraformVersion) AtLeast(major, minor int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	return v.Minor >= minor
+}
+
+// Features introduced over the life of state format version 4
+func (v terraformVersion) supportsStateV4() bool                 { return v.AtLeast(0, 12) }
+func (v terraformVersion) supportsProviderSourceAddresses() bool { return v.AtLeast(0, 13) }
+func (v terraformVersion) supportsSensitiveAttributes() bool     { return v.AtLeast(0, 14) }
+func (v terraformVersion) supportsCheckResults() bool            { return v.AtLeast(1, 5) }
+func (v terraformVersion) supportsIdentity() bool                { return v.AtLeast(1, 12) }
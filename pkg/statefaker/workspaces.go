@@ -0,0 +1,248 @@
+package statefaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+)
+
+// Workspace is a generated state along with the name of the workspace it
+// belongs to
+type Workspace struct {
+	Name  string
+	State *StateV4
+}
+
+// WorkspaceSize selects the distribution of the number of resources in each
+// workspace of an organization
+type WorkspaceSize int
+
+const (
+	// WorkspaceSizeFixed gives every workspace the configured number of
+	// resources
+	WorkspaceSizeFixed WorkspaceSize = iota
+	// WorkspaceSizeLogNormal draws the number of resources from a log-normal
+	// distribution with the configured number of resources as its median,
+	// giving many small workspaces and a few very large ones
+	WorkspaceSizeLogNormal
+	// WorkspaceSizePareto draws the number of resources from a Pareto
+	// distribution with the configured number of resources as its median,
+	// where a handful of enormous workspaces hold most of the resources
+	WorkspaceSizePareto
+)
+
+// ParseWorkspaceSize parses the name of a WorkspaceSize as used on the command line
+func ParseWorkspaceSize(name string) (WorkspaceSize, error) {
+	switch name {
+	case "fixed":
+		return WorkspaceSizeFixed, nil
+	case "lognormal":
+		return WorkspaceSizeLogNormal, nil
+	case "pareto":
+		return WorkspaceSizePareto, nil
+	}
+	return WorkspaceSizeFixed, fmt.Errorf("unknown workspace size %q, expected fixed, lognormal or pareto", name)
+}
+
+// Shape parameters of the workspace size distributions, and the most
+// resources any workspace is given relative to the median
+const (
+	workspaceSizeSigma = 1.5
+	workspaceSizeAlpha = 1.16 // the 80/20 rule
+	workspaceSizeLimit = 1000
+)
+
+// sampleResources returns the number of resources for a workspace given the
+// median number of resources
+func (size WorkspaceSize) sampleResources(median int) int {
+	if median < 1 {
+		return median
+	}
+
+	var n float64
+	switch size {
+	case WorkspaceSizeLogNormal:
+		n = math.Exp(math.Log(float64(median)) + workspaceSizeSigma*rand.NormFloat64())
+	case WorkspaceSizePareto:
+		scale := float64(median) / math.Pow(2, 1/workspaceSizeAlpha)
+		n = scale / math.Pow(1-rand.Float64(), 1/workspaceSizeAlpha)
+	default:
+		return median
+	}
+
+	return max(1, min(int(math.Round(n)), median*workspaceSizeLimit))
+}
+
+// Parts of the workspace naming convention <team>-<component>-<env>
+var (
+	workspaceTeams      = []string{"platform", "payments", "identity", "data", "ml", "web", "mobile", "security", "search", "growth"}
+	workspaceComponents = []string{"networking", "dns", "iam", "database", "cache", "queue", "api", "frontend", "workers", "observability", "storage", "cluster"}
+	workspaceEnvs       = []string{"prod", "staging", "dev", "qa"}
+)
+
+// workspaceOutputs are the outputs of an earlier workspace that later
+// workspaces can consume with terraform_remote_state
+type workspaceOutputs struct {
+	name    string
+	outputs map[string]OutputV4
+}
+
+// NewFakeWorkspaces generates count workspace states, some of which consume
+// the outputs of others. See GenerateWorkspaces.
+func NewFakeWorkspaces(count int, opts ...Option) ([]*Workspace, error) {
+	var workspaces []*Workspace
+	err := GenerateWorkspaces(count, func(workspace *Workspace) error {
+		workspaces = append(workspaces, workspace)
+		return nil
+	}, opts...)
+	return workspaces, err
+}
+
+// GenerateWorkspaces generates count workspace states in an organization,
+// passing each to emit as soon as it is generated so that large sets never
+// need to be held in memory at once. The number of resources in each
+// workspace follows the configured WorkspaceSize. Workspaces are given a chance to
+// contain terraform_remote_state data resources whose outputs mirror the
+// real outputs of workspaces generated before them, so the dependencies
+// between workspaces never form a cycle.
+func GenerateWorkspaces(count int, emit func(*Workspace) error, opts ...Option) error {
+	options := ApplyOptions(opts...)
+
+	organization := options.Organization
+	if organization == "" {
+		organization = generateOrganizationName()
+	}
+
+	var earlier []workspaceOutputs
+	names := make(map[string]bool)
+
+	for i := 0; i < count; i++ {
+		resources := options.WorkspaceSize.sampleResources(options.NumResources)
+		state, err := NewFakeStateV4(append(opts[:len(opts):len(opts)], WithResources(resources))...)
+		if err != nil {
+			return err
+		}
+		version, err := parseTerraformVersion(state.TerraformVersion)
+		if err != nil {
+			return err
+		}
+
+		base := generateWorkspaceName()
+		name := base
+		for n := 2; names[name]; n++ {
+			name = fmt.Sprintf("%s-%d", base, n)
+		}
+		names[name] = true
+
+		if len(earlier) > 0 && rand.IntN(100) < options.RemoteStateChance {
+			// Consume between one and three earlier workspaces
+			for _, j := range rand.Perm(len(earlier))[:min(len(earlier), rand.IntN(3)+1)] {
+				resource, err := generateRemoteStateResource(organization, earlier[j], version)
+				if err != nil {
+					return err
+				}
+				state.Resources = append(state.Resources, resource)
+			}
+		}
+
+		outputs, err := decodeOutputs(state)
+		if err != nil {
+			return err
+		}
+		if len(outputs) > 0 {
+			earlier = append(earlier, workspaceOutputs{name: name, outputs: outputs})
+		}
+
+		if err := emit(&Workspace{Name: name, State: state}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func generateOrganizationName() string {
+	prefixes := []string{"acme", "globex", "initech", "umbrella", "hooli", "stark", "wayne"}
+	suffixes := []string{"corp", "inc", "labs", "industries", "io"}
+	return fmt.Sprintf("%s-%s", prefixes[rand.IntN(len(prefixes))], suffixes[rand.IntN(len(suffixes))])
+}
+
+func generateWorkspaceName() string {
+	return fmt.Sprintf("%s-%s-%s",
+		workspaceTeams[rand.IntN(len(workspaceTeams))],
+		workspaceComponents[rand.IntN(len(workspaceComponents))],
+		workspaceEnvs[rand.IntN(len(workspaceEnvs))])
+}
+
+func decodeOutputs(state *StateV4) (map[string]OutputV4, error) {
+	outputs := make(map[string]OutputV4, len(state.Outputs))
+	for name, raw := range state.Outputs {
+		var output OutputV4
+		if err := json.Unmarshal(raw, &output); err != nil {
+			return nil, fmt.Errorf("failed to decode output %s: %w", name, err)
+		}
+		outputs[name] = output
+	}
+	return outputs, nil
+}
+
+// generateRemoteStateResource generates a terraform_remote_state data
+// resource reading the outputs of another workspace. Its config and outputs
+// attributes are dynamically typed, so terraform stores them along with
+// their types.
+func generateRemoteStateResource(organization string, source workspaceOutputs, version terraformVersion) (ResourceV4, error) {
+	values := make(map[string]json.RawMessage)
+	types := make(map[string]json.RawMessage)
+	for name, output := range source.outputs {
+		values[name] = output.Value
+		types[name] = output.Type
+	}
+
+	attributes := map[string]any{
+		"backend": "remote",
+		"config": map[string]any{
+			"value": map[string]any{
+				"organization": organization,
+				"workspaces": map[string]string{
+					"name": source.name,
+				},
+			},
+			"type": []any{"object", map[string]any{
+				"organization": "string",
+				"workspaces":   []any{"object", map[string]string{"name": "string"}},
+			}},
+		},
+		"defaults": nil,
+		"outputs": map[string]any{
+			"value": values,
+			"type":  []any{"object", types},
+		},
+		"workspace": nil,
+	}
+
+	b, err := json.Marshal(attributes)
+	if err != nil {
+		return ResourceV4{}, fmt.Errorf("failed to marshal remote state attributes: %w", err)
+	}
+
+	instance := InstanceV4{
+		Attributes: b,
+	}
+	if version.supportsSensitiveAttributes() {
+		instance.SensitiveAttributes = json.RawMessage("[]")
+	}
+
+	provider := "provider.terraform"
+	if version.supportsProviderSourceAddresses() {
+		provider = `provider["terraform.io/builtin/terraform"]`
+	}
+	return ResourceV4{
+		Mode:      "data",
+		Type:      "terraform_remote_state",
+		Name:      strings.ReplaceAll(source.name, "-", "_"),
+		Provider:  provider,
+		Instances: []InstanceV4{instance},
+	}, nil
+}
@@ -0,0 +1,101 @@
+package statefaker
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestNewFakeWorkspaces(t *testing.T) {
+	workspaces, err := NewFakeWorkspaces(10, WithResources(5), WithRemoteStateChance(100), WithOrganization("acme-corp"))
+	if err != nil {
+		t.Fatalf("failed to generate workspaces: %v", err)
+	}
+	if len(workspaces) != 10 {
+		t.Fatalf("expected 10 workspaces, got %d", len(workspaces))
+	}
+
+	byName := make(map[string]*StateV4)
+	remoteStates := 0
+	for _, workspace := range workspaces {
+		if byName[workspace.Name] != nil {
+			t.Fatalf("duplicate workspace name %s", workspace.Name)
+		}
+
+		for _, r := range workspace.State.Resources {
+			if r.Type != "terraform_remote_state" {
+				continue
+			}
+			remoteStates++
+
+			var attributes struct {
+				Config struct {
+					Value struct {
+						Organization string `json:"organization"`
+						Workspaces   struct {
+							Name string `json:"name"`
+						} `json:"workspaces"`
+					} `json:"value"`
+				} `json:"config"`
+				Outputs struct {
+					Value map[string]json.RawMessage `json:"value"`
+				} `json:"outputs"`
+			}
+			if err := json.Unmarshal(r.Instances[0].Attributes, &attributes); err != nil {
+				t.Fatalf("failed to decode remote state attributes: %v", err)
+			}
+			if attributes.Config.Value.Organization != "acme-corp" {
+				t.Errorf("expected organization acme-corp, got %s", attributes.Config.Value.Organization)
+			}
+
+			// Only workspaces generated earlier can be consumed
+			source := byName[attributes.Config.Value.Workspaces.Name]
+			if source == nil {
+				t.Fatalf("%s reads unknown or later workspace %s", workspace.Name, attributes.Config.Value.Workspaces.Name)
+			}
+			outputs, err := decodeOutputs(source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(outputs) != len(attributes.Outputs.Value) {
+				t.Errorf("expected %d outputs from %s, got %d", len(outputs), attributes.Config.Value.Workspaces.Name, len(attributes.Outputs.Value))
+			}
+			for name, output := range outputs {
+				var expected, actual bytes.Buffer
+				json.Compact(&expected, output.Value)
+				json.Compact(&actual, attributes.Outputs.Value[name])
+				if expected.String() != actual.String() {
+					t.Errorf("output %s of %s does not match the workspace's real output", name, attributes.Config.Value.Workspaces.Name)
+				}
+			}
+		}
+
+		byName[workspace.Name] = workspace.State
+	}
+
+	if remoteStates == 0 {
+		t.Error("expected some terraform_remote_state resources")
+	}
+}
+
+func TestWorkspaceSize(t *testing.T) {
+	for _, size := range []WorkspaceSize{WorkspaceSizeFixed, WorkspaceSizeLogNormal, WorkspaceSizePareto} {
+		samples := make([]int, 2001)
+		for i := range samples {
+			samples[i] = size.sampleResources(50)
+			if samples[i] < 1 || samples[i] > 50*workspaceSizeLimit {
+				t.Fatalf("%d: sample %d out of range", size, samples[i])
+			}
+		}
+		slices.Sort(samples)
+
+		// The configured number of resources is the median
+		if median := samples[len(samples)/2]; median < 40 || median > 60 {
+			t.Errorf("%d: expected a median near 50, got %d", size, median)
+		}
+		if size != WorkspaceSizeFixed && samples[len(samples)-1] < 500 {
+			t.Errorf("%d: expected a long tail of large workspaces, largest was %d", size, samples[len(samples)-1])
+		}
+	}
+}